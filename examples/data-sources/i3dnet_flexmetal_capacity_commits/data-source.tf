@@ -0,0 +1,8 @@
+# Get the committed capacity in Rotterdam.
+data "i3dnet_flexmetal_capacity_commits" "rotterdam" {
+  location = "EU: Rotterdam"
+}
+
+output "available_commits" {
+  value = { for c in data.i3dnet_flexmetal_capacity_commits.rotterdam.commits : c.instance_type => c.available }
+}
@@ -0,0 +1,18 @@
+# Find the smallest instance type in Rotterdam with at least 16 cores and
+# 64 GB of memory.
+data "i3dnet_flexmetal_instance_types" "small" {
+  location   = "EU: Rotterdam"
+  min_cores  = 16
+  min_memory = 64
+  smallest   = true
+}
+
+resource "i3dnet_flexmetal_server" "web" {
+  name          = "web-1"
+  location      = data.i3dnet_flexmetal_instance_types.small.location
+  instance_type = data.i3dnet_flexmetal_instance_types.small.instance_types[0].name
+  os = {
+    slug = "ubuntu-2404-lts"
+  }
+  ssh_key = ["<YOUR-PUBLIC-SSH-KEY>"]
+}
@@ -0,0 +1,9 @@
+# Get the on demand and commit quotas of bm7.std.8 servers in Rotterdam.
+data "i3dnet_flexmetal_quota_usage" "rotterdam" {
+  location      = "EU: Rotterdam"
+  instance_type = "bm7.std.8"
+}
+
+output "remaining_on_demand" {
+  value = one([for q in data.i3dnet_flexmetal_quota_usage.rotterdam.quotas : q.remaining if q.quota_type == "onDemand"])
+}
@@ -0,0 +1,8 @@
+# Look up a server ordered outside this configuration by its host name.
+data "i3dnet_flexmetal_server" "bastion" {
+  name = "bastion-01"
+}
+
+output "bastion_ips" {
+  value = data.i3dnet_flexmetal_server.bastion.ip_addresses
+}
@@ -0,0 +1,10 @@
+# List every delivered server tagged env:prod, including servers created
+# outside Terraform.
+data "i3dnet_flexmetal_servers" "prod" {
+  status = "delivered"
+  tags   = ["env:prod"]
+}
+
+output "prod_ips" {
+  value = flatten(data.i3dnet_flexmetal_servers.prod.servers[*].ip_addresses)
+}
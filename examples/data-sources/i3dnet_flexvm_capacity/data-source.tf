@@ -0,0 +1,15 @@
+# Check how much room is left in a FlexVM Cloud.
+data "i3dnet_flexvm_capacity" "cloud" {
+  cloud_id = "019256ab-1554-73a7-b091-f024b0a724ea"
+}
+
+# Without cloud_id, the capacity of all Clouds of the organization is returned.
+data "i3dnet_flexvm_capacity" "organization" {}
+
+output "available_vcpu" {
+  value = data.i3dnet_flexvm_capacity.cloud.vcpu.available
+}
+
+output "available_memory" {
+  value = data.i3dnet_flexvm_capacity.organization.memory.available
+}
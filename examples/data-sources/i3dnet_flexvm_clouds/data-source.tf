@@ -0,0 +1,13 @@
+# Find a team's Cloud by name instead of passing its UUID around.
+data "i3dnet_flexvm_clouds" "team" {
+  name_regex = "^team-a-build$"
+  site       = "frmtl1"
+}
+
+resource "i3dnet_flexvm_vm" "example" {
+  cloud_id           = data.i3dnet_flexvm_clouds.team.clouds[0].id
+  name               = "example-vm"
+  instance_type_name = "vm.4c.8g"
+  image_name         = "ubuntu-2404-server-amd64"
+  ssh_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHwdgjY0AlmkeLknBpoVmJg/quNSifyBHEK1MREpV4Ri john.doe@i3d.net"]
+}
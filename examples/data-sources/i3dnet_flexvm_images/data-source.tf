@@ -0,0 +1,14 @@
+# Find the most recent Ubuntu image.
+data "i3dnet_flexvm_images" "ubuntu" {
+  os          = "ubuntu"
+  os_type     = "linux"
+  most_recent = true
+}
+
+resource "i3dnet_flexvm_vm" "example" {
+  cloud_id           = "019256ab-1554-73a7-b091-f024b0a724ea"
+  name               = "example-vm"
+  instance_type_name = "vm.4c.8g"
+  image_name         = data.i3dnet_flexvm_images.ubuntu.images[0].name
+  ssh_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHwdgjY0AlmkeLknBpoVmJg/quNSifyBHEK1MREpV4Ri john.doe@i3d.net"]
+}
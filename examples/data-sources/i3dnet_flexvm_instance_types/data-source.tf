@@ -0,0 +1,15 @@
+# Find the smallest instance type with at least 4 vCPUs and 8 GB of memory.
+data "i3dnet_flexvm_instance_types" "small" {
+  min_vcpu   = 4
+  min_memory = 8192
+  smallest   = true
+}
+
+# List all instance types with a GPU.
+data "i3dnet_flexvm_instance_types" "gpu" {
+  pci_device_type = "gpu"
+}
+
+output "gpu_instance_types" {
+  value = [for it in data.i3dnet_flexvm_instance_types.gpu.instance_types : it.name]
+}
@@ -0,0 +1,9 @@
+# Report the VM hours consumed in January, per team tag.
+data "i3dnet_flexvm_usage_report" "january" {
+  start = "2026-01-01T00:00:00Z"
+  end   = "2026-02-01T00:00:00Z"
+}
+
+output "vm_hours_by_tag" {
+  value = { for t in data.i3dnet_flexvm_usage_report.january.by_tag : t.tag => t.total_vm_hours }
+}
@@ -0,0 +1,11 @@
+# List the running VMs within a FlexVM Cloud, including VMs created outside Terraform.
+data "i3dnet_flexvm_vms" "running" {
+  cloud_id = "019256ab-1554-73a7-b091-f024b0a724ea"
+  status   = "running"
+}
+
+output "public_ips" {
+  value = flatten([
+    for vm in data.i3dnet_flexvm_vms.running.vms : [for ip in vm.ips : ip.address if ip.public]
+  ])
+}
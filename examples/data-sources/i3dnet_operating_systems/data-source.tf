@@ -0,0 +1,23 @@
+# Find the most recent Talos Omni version.
+data "i3dnet_operating_systems" "talos" {
+  family      = "talos-omni"
+  most_recent = true
+}
+
+resource "i3dnet_flexmetal_server" "talos" {
+  name          = "talos-1"
+  location      = "EU: Rotterdam"
+  instance_type = "bm7.std.8"
+  os = {
+    slug = data.i3dnet_operating_systems.talos.operating_systems[0].slug
+  }
+}
+
+# List all Windows versions.
+data "i3dnet_operating_systems" "windows" {
+  os_type = "windows"
+}
+
+output "windows_slugs" {
+  value = [for os in data.i3dnet_operating_systems.windows.operating_systems : os.slug]
+}
@@ -0,0 +1,28 @@
+resource "i3dnet_flexmetal_server" "windows" {
+  name          = "TerraFlex-Windows"
+  location      = "EU: Rotterdam"
+  instance_type = "bm7.std.8"
+  os = {
+    slug = "windows-2022"
+  }
+}
+
+# The root password is fetched on every run and never stored in the plan or state.
+ephemeral "i3dnet_flexmetal_server_root_password" "windows" {
+  uuid = i3dnet_flexmetal_server.windows.uuid
+}
+
+resource "terraform_data" "bootstrap" {
+  triggers_replace = [i3dnet_flexmetal_server.windows.uuid]
+
+  connection {
+    type     = "winrm"
+    host     = i3dnet_flexmetal_server.windows.ip_addresses[0].ip_address
+    user     = "Administrator"
+    password = ephemeral.i3dnet_flexmetal_server_root_password.windows.root_password
+  }
+
+  provisioner "remote-exec" {
+    inline = ["powershell.exe -Command Install-WindowsFeature -Name Web-Server"]
+  }
+}
@@ -116,7 +116,19 @@ resource "i3dnet_flexmetal_server" "my-custom-ipxe" {
   location      = "EU: Rotterdam"
   instance_type = "bm7.std.8"
   os = {
-    slug          = "custom-ipxe"
-    ipxeScriptUrl = "https://example.org/custom-ipxe.cfg"
+    slug            = "custom-ipxe"
+    ipxe_script_url = "https://example.org/custom-ipxe.cfg"
   }
-}
\ No newline at end of file
+}
+
+# Fail the plan when it orders more bm7.std.8 servers in Rotterdam than the
+# on demand quota has left.
+resource "i3dnet_flexmetal_server" "my-checked-server" {
+  name          = "MyCheckedServer"
+  location      = "EU: Rotterdam"
+  instance_type = "bm7.std.8"
+  os = {
+    slug = "ubuntu-2404-lts"
+  }
+  quota_check = "error"
+}
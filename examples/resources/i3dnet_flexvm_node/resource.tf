@@ -6,4 +6,7 @@ data "i3dnet_flexvm_cloud" "my-cloud" {
 # Cloud's instance type and location, so cloud_id is the only required input.
 resource "i3dnet_flexvm_node" "my-node" {
   cloud_id = data.i3dnet_flexvm_cloud.my-cloud.id
+
+  # Refuse to destroy the Node while VMs are placed on it (the default).
+  on_destroy_with_vms = "fail"
 }
@@ -0,0 +1,2 @@
+# Importing by Cloud UUID adopts every Node of the Cloud into the pool.
+terraform import i3dnet_flexvm_node_pool.my-pool cloud_id
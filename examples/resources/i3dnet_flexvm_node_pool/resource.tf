@@ -0,0 +1,10 @@
+data "i3dnet_flexvm_cloud" "my-cloud" {
+  id = "019256ab-1554-73a7-b091-f024b0a724ea"
+}
+
+# Scale a FlexVM Cloud by changing size: Nodes are added or removed
+# concurrently, and only Nodes without VMs are removed.
+resource "i3dnet_flexvm_node_pool" "my-pool" {
+  cloud_id = data.i3dnet_flexvm_cloud.my-cloud.id
+  size     = 3
+}
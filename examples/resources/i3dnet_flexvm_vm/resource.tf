@@ -13,6 +13,10 @@ resource "i3dnet_flexvm_vm" "my-vm" {
 
   # Optional free-form labels for grouping in the monthly usage report.
   tags = ["project:odyssey", "env:build"]
+
+  # Optional power state, "running" or "stopped". Changing it starts or stops
+  # the VM in place.
+  power_state = "running"
 }
 
 # Alternatively, provide cloud-init user-data from a file instead of ssh_keys.
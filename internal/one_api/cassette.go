@@ -0,0 +1,284 @@
+package one_api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CassetteMode selects whether a Cassette records or replays API exchanges.
+type CassetteMode int
+
+const (
+	// CassetteRecord sends every request to the API and records the exchange.
+	CassetteRecord CassetteMode = iota + 1
+	// CassetteReplay answers every request from the recorded exchanges,
+	// without any network traffic.
+	CassetteReplay
+)
+
+// ErrNoRecordedInteraction is returned in replay mode for a request the
+// cassette holds no exchange for. Such requests are not retried.
+var ErrNoRecordedInteraction = errors.New("no recorded interaction")
+
+// cassetteOmittedHeaders are never written to a cassette: they hold
+// credentials, or change on every recording and would only add noise to the
+// cassette diff.
+var cassetteOmittedHeaders = []string{
+	"PRIVATE-TOKEN",
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Date",
+}
+
+// Cassette is a file of recorded One API exchanges. In record mode the Client
+// talks to the API and every exchange is added to the cassette, which is
+// written by Save. In replay mode the Client is answered from the cassette.
+//
+// A Cassette may be shared by several Clients, e.g. the ones the provider
+// creates for every Terraform command of an acceptance test.
+//
+// Cassettes are meant to be committed: credential headers are never recorded,
+// and the secret JSON fields of bodies are redacted as in the debug logs.
+type Cassette struct {
+	path string
+	mode CassetteMode
+
+	mu           sync.Mutex
+	interactions []interaction
+	used         []bool
+}
+
+// interaction is a single recorded request and its response.
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+// NewCassette returns the cassette stored at path. In replay mode the file is
+// loaded and must exist; in record mode the cassette starts out empty and
+// replaces the file on Save.
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode}
+
+	switch mode {
+	case CassetteRecord:
+	case CassetteReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read cassette: %w", err)
+		}
+
+		var file cassetteFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("could not parse cassette %s: %w", path, err)
+		}
+
+		c.interactions = file.Interactions
+		c.used = make([]bool, len(file.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %d", mode)
+	}
+
+	return c, nil
+}
+
+// WithCassette makes the Client record its API exchanges to, or replay them
+// from, cassette.
+func WithCassette(cassette *Cassette) Option {
+	return func(c *Client) {
+		c.cassette = cassette
+	}
+}
+
+// Save writes the recorded exchanges to the cassette file. It does nothing in
+// replay mode.
+func (c *Cassette) Save() error {
+	if c.mode != CassetteRecord {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("could not encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("could not create cassette directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("could not write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// roundTripper returns the RoundTripper recording the exchanges of next, or
+// replaying them, depending on the cassette mode.
+func (c *Cassette) roundTripper(next http.RoundTripper) http.RoundTripper {
+	return &cassetteRoundTripper{cassette: c, next: next}
+}
+
+type cassetteRoundTripper struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+func (rt *cassetteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if rt.cassette.mode == CassetteReplay {
+		return rt.cassette.replay(req, recorded)
+	}
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rt.cassette.record(interaction{
+		Request: recorded,
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    scrubHeaders(resp.Header),
+			Body:       string(redactBody(body)),
+		},
+	})
+
+	return resp, nil
+}
+
+func (c *Cassette) record(i interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, i)
+}
+
+// replay answers req with the first unused exchange recorded for the same
+// request. Requests are matched on method, path, query, body and RANGED-DATA
+// header. Once every matching exchange is used, the last one is served again,
+// so a status that was polled fewer times during recording still settles.
+func (c *Cassette) replay(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := -1
+	for i, candidate := range c.interactions {
+		if !candidate.Request.matches(recorded) {
+			continue
+		}
+
+		last = i
+		if !c.used[i] {
+			c.used[i] = true
+			return candidate.Response.toHTTP(req), nil
+		}
+	}
+
+	if last < 0 {
+		return nil, fmt.Errorf("cassette %s: %w for %s %s", c.path, ErrNoRecordedInteraction, recorded.Method, recorded.URL)
+	}
+
+	return c.interactions[last].Response.toHTTP(req), nil
+}
+
+// recordRequest captures req without its credentials and with its secret
+// JSON fields redacted, restoring the body so it can still be sent. Replayed
+// requests are matched on the redacted body too.
+func recordRequest(req *http.Request) (recordedRequest, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return recordedRequest{}, fmt.Errorf("error reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return recordedRequest{
+		Method:  req.Method,
+		URL:     req.URL.RequestURI(),
+		Headers: scrubHeaders(req.Header),
+		Body:    string(redactBody(body)),
+	}, nil
+}
+
+func (r recordedRequest) matches(other recordedRequest) bool {
+	return r.Method == other.Method &&
+		r.URL == other.URL &&
+		r.Body == other.Body &&
+		r.Headers.Get(rangedDataHeader) == other.Headers.Get(rangedDataHeader)
+}
+
+func (r recordedResponse) toHTTP(req *http.Request) *http.Response {
+	header := r.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// scrubHeaders returns a copy of h without the cassetteOmittedHeaders.
+func scrubHeaders(h http.Header) http.Header {
+	scrubbed := h.Clone()
+	for _, name := range cassetteOmittedHeaders {
+		scrubbed.Del(name)
+	}
+
+	if len(scrubbed) == 0 {
+		return nil
+	}
+
+	return scrubbed
+}
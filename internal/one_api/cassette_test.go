@@ -0,0 +1,154 @@
+package one_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	const apiKey = "secret-api-key"
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, apiKey, r.Header.Get("PRIVATE-TOKEN"))
+
+		switch r.URL.Path {
+		case "/v3/flexMetal/location":
+			_, _ = w.Write([]byte(`[{"id": 6, "name": "EU: Rotterdam"}]`))
+		case "/v3/flexMetal/tags":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`[{"tag": "web"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode": 0, "errorMessage": "Not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	recorder, err := NewCassette(path, CassetteRecord)
+	require.NoError(t, err)
+
+	c, err := NewClient(apiKey, srv.URL, WithCassette(recorder))
+	require.NoError(t, err)
+
+	locations, err := c.ListLocations(ctx)
+	require.NoError(t, err)
+	tag, err := c.CreateTag(ctx, "web")
+	require.NoError(t, err)
+	_, err = c.GetTag(ctx, "missing")
+	require.True(t, IsNotFound(err))
+
+	require.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), apiKey)
+
+	// The replaying client never reaches the server.
+	recorded := requests.Load()
+	srv.Close()
+
+	player, err := NewCassette(path, CassetteReplay)
+	require.NoError(t, err)
+
+	c, err = NewClient(apiKey, srv.URL, WithCassette(player))
+	require.NoError(t, err)
+
+	replayedLocations, err := c.ListLocations(ctx)
+	require.NoError(t, err)
+	require.Equal(t, locations, replayedLocations)
+
+	replayedTag, err := c.CreateTag(ctx, "web")
+	require.NoError(t, err)
+	require.Equal(t, tag, replayedTag)
+
+	_, err = c.GetTag(ctx, "missing")
+	require.True(t, IsNotFound(err))
+
+	// Once used up, the last matching exchange is served again.
+	_, err = c.ListLocations(ctx)
+	require.NoError(t, err)
+
+	// A request that was never recorded fails without retries.
+	_, err = c.CreateTag(ctx, "other")
+	require.ErrorIs(t, err, ErrNoRecordedInteraction)
+
+	require.Equal(t, recorded, requests.Load())
+}
+
+func TestCassetteRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/flexMetal/servers":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"uuid": "abc", "postInstallScript": "echo secret-script"}`))
+		case "/v3/flexMetal/servers/abc/rootPassword":
+			_, _ = w.Write([]byte(`{"rootPassword": "secret-password"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.json")
+	createReq := CreateServerReq{Name: "web", PostInstallScript: "echo secret-script"}
+
+	recorder, err := NewCassette(path, CassetteRecord)
+	require.NoError(t, err)
+
+	c, err := NewClient("api-key", srv.URL, WithCassette(recorder))
+	require.NoError(t, err)
+
+	// The recording client still sends and receives the secrets.
+	_, err = c.CreateServer(ctx, createReq)
+	require.NoError(t, err)
+	password, err := c.GetServerRootPassword(ctx, "abc")
+	require.NoError(t, err)
+	require.Equal(t, "secret-password", password.RootPassword)
+
+	require.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{"secret-script", "secret-password"} {
+		require.NotContains(t, string(data), secret)
+	}
+
+	player, err := NewCassette(path, CassetteReplay)
+	require.NoError(t, err)
+
+	c, err = NewClient("api-key", srv.URL, WithCassette(player))
+	require.NoError(t, err)
+
+	// Requests are matched on their redacted body.
+	server, err := c.CreateServer(ctx, createReq)
+	require.NoError(t, err)
+	require.Equal(t, "abc", server.Uuid)
+
+	password, err = c.GetServerRootPassword(ctx, "abc")
+	require.NoError(t, err)
+	require.Equal(t, redactedMask, password.RootPassword)
+}
+
+func TestNewCassetteReplayRequiresFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCassette(filepath.Join(t.TempDir(), "missing.json"), CassetteReplay)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -6,20 +6,27 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 type Client struct {
-	apiKey  string
-	baseURL *url.URL
+	apiKey     string
+	baseURL    *url.URL
+	retry      RetryConfig
+	transport  TransportConfig
+	httpClient *http.Client
+	limiter    *rateLimiter
+	cassette   *Cassette
 }
 
+// Option configures optional Client behaviour in NewClient.
+type Option func(*Client)
+
 const (
 	DefaultBaseURL = "https://api.i3d.net"
 	apiVersion     = "v3"
 )
 
-func NewClient(apiKey string, rawBaseURL string) (*Client, error) {
+func NewClient(apiKey string, rawBaseURL string, opts ...Option) (*Client, error) {
 	if rawBaseURL == "" {
 		rawBaseURL = DefaultBaseURL
 	}
@@ -31,26 +38,28 @@ func NewClient(apiKey string, rawBaseURL string) (*Client, error) {
 
 	baseURL = baseURL.JoinPath(apiVersion)
 
-	return &Client{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-	}, nil
-}
+	c := &Client{
+		apiKey:    apiKey,
+		baseURL:   baseURL,
+		retry:     DefaultRetryConfig,
+		transport: DefaultTransportConfig,
+		limiter:   newRateLimiter(0),
+	}
 
-func (c *Client) callAPI(ctx context.Context, method, endpoint, path string, body []byte, queryParams map[string]string) (*http.Response, error) {
-	return c.callAPIWithHeaders(ctx, method, endpoint, path, body, queryParams, nil)
-}
+	for _, opt := range opts {
+		opt(c)
+	}
 
-// callAPIWithHeaders behaves like callAPI but also sets the provided request
-// headers in addition to the default ones. It is useful for headers such as
-// the RANGED-DATA pagination header.
-func (c *Client) callAPIWithHeaders(ctx context.Context, method, endpoint, path string, body []byte,
-	queryParams, headers map[string]string) (*http.Response, error) {
-	client := &http.Client{
-		Transport: &loggingRoundTripper{next: http.DefaultTransport, ctx: ctx},
-		Timeout:   90 * time.Second,
+	c.httpClient, err = newHTTPClient(c.transport, c.cassette)
+	if err != nil {
+		return nil, fmt.Errorf("could not create http client: %w", err)
 	}
 
+	return c, nil
+}
+
+// apiURL builds the full request URL from the endpoint, path and query params.
+func (c *Client) apiURL(endpoint, path string, queryParams url.Values) string {
 	apiURL := c.baseURL
 	if endpoint != "" {
 		apiURL = apiURL.JoinPath(endpoint)
@@ -61,25 +70,67 @@ func (c *Client) callAPIWithHeaders(ctx context.Context, method, endpoint, path
 
 	query := apiURL.Query()
 	for k, v := range queryParams {
-		query.Set(k, v)
+		query[k] = v
 	}
 	apiURL.RawQuery = query.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, method, apiURL.String(), bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
+	return apiURL.String()
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("PRIVATE-TOKEN", c.apiKey)
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
+// send performs the HTTP request, retrying transient failures according to the
+// Client's RetryConfig when retryable is set. See shouldRetry for what counts
+// as a transient failure. Every attempt is subject to the Client's rate limit.
+func (c *Client) send(ctx context.Context, method, apiURL string, body []byte, headers map[string]string, retryable bool) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, fmt.Errorf("failed to do HTTP request: %w", err)
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to do HTTP request: %w", err)
-	}
+		// The request is rebuilt on every attempt, as its body is consumed when sent.
+		req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("PRIVATE-TOKEN", c.apiKey)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
 
-	return resp, nil
+		resp, err := c.httpClient.Do(req)
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			// The API key is throttled, so hold back every caller of the Client,
+			// not only this one. A pause beyond the retry limit would freeze
+			// them all, so the 429 is returned to the caller instead.
+			if wait, ok := retryAfter(resp); ok && wait <= c.retry.retryAfterLimit() {
+				c.limiter.pause(wait)
+			}
+		}
+
+		if !retryable || attempt >= c.retry.MaxRetries || !shouldRetry(ctx, resp, err) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to do HTTP request: %w", err)
+			}
+			return resp, nil
+		}
+
+		wait, ok := c.retry.backoff(attempt, resp)
+		if !ok || !deadlineAllows(ctx, wait) {
+			// The API asks to wait longer than we are willing to, or the
+			// context expires before the next attempt could be made, so return
+			// the outcome of this one instead of a context error.
+			if err != nil {
+				return nil, fmt.Errorf("failed to do HTTP request: %w", err)
+			}
+			return resp, nil
+		}
+
+		logRetry(ctx, method, apiURL, attempt+1, wait, resp, err)
+		drainAndClose(resp)
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, fmt.Errorf("failed to do HTTP request: %w", err)
+		}
+	}
 }
@@ -0,0 +1,71 @@
+package one_api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned by the Client when the One API responds with a status
+// >= 400. Callers can inspect it with errors.As, or use one of the Is* helpers
+// below.
+type APIError struct {
+	StatusCode   int             `json:"-"`
+	ErrorCode    int             `json:"errorCode"`
+	ErrorMessage string          `json:"errorMessage"`
+	Errors       []APIFieldError `json:"errors"`
+}
+
+// APIFieldError describes a validation failure of a single request property.
+type APIFieldError struct {
+	Property string `json:"property"`
+	Message  string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "received %d status code", e.StatusCode)
+	if e.ErrorCode != 0 {
+		fmt.Fprintf(&b, " (error code %d)", e.ErrorCode)
+	}
+	if e.ErrorMessage != "" {
+		fmt.Fprintf(&b, ": %s", e.ErrorMessage)
+	}
+	for _, v := range e.Errors {
+		fmt.Fprintf(&b, "; %s: %s", v.Property, v.Message)
+	}
+	return b.String()
+}
+
+// IsNotFound reports whether err is an *APIError with a 404 status code.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an *APIError with a 409 status code.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsFlexvmVMInTransition reports whether err says the VM cannot be changed
+// because it is in a transitional state, see FlexvmErrCodeVMInTransition.
+func IsFlexvmVMInTransition(err error) bool {
+	return hasErrorCode(err, http.StatusUnprocessableEntity, FlexvmErrCodeVMInTransition)
+}
+
+// IsFlexvmVMTerminal reports whether err says the VM is already failed or
+// deleted, see FlexvmErrCodeVMTerminal.
+func IsFlexvmVMTerminal(err error) bool {
+	return hasErrorCode(err, http.StatusUnprocessableEntity, FlexvmErrCodeVMTerminal)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+func hasErrorCode(err error, statusCode, errorCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode && apiErr.ErrorCode == errorCode
+}
@@ -0,0 +1,81 @@
+package one_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReturnsAPIError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantNotFound     bool
+		wantConflict     bool
+		wantInTransition bool
+		wantMessage      string
+	}{
+		{
+			name:         "not found",
+			status:       http.StatusNotFound,
+			body:         `{"errorCode": 0, "errorMessage": "VM not found"}`,
+			wantNotFound: true,
+			wantMessage:  "VM not found",
+		},
+		{
+			name:         "conflict",
+			status:       http.StatusConflict,
+			body:         `{"errorMessage": "VM is already being deleted"}`,
+			wantConflict: true,
+			wantMessage:  "VM is already being deleted",
+		},
+		{
+			name:             "vm in transition",
+			status:           http.StatusUnprocessableEntity,
+			body:             `{"errorCode": 90001, "errorMessage": "VM is provisioning"}`,
+			wantInTransition: true,
+			wantMessage:      "VM is provisioning",
+		},
+		{
+			name:        "body that is not an error response",
+			status:      http.StatusBadRequest,
+			body:        `<html>bad request</html>`,
+			wantMessage: "Received 400 status code.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient("key", srv.URL)
+			require.NoError(t, err)
+
+			err = c.FlexvmDeleteVM(context.Background(), "cloud", "vm")
+			require.Error(t, err)
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, tt.status, apiErr.StatusCode)
+			require.Equal(t, tt.wantMessage, apiErr.ErrorMessage)
+
+			require.Equal(t, tt.wantNotFound, IsNotFound(err))
+			require.Equal(t, tt.wantConflict, IsConflict(err))
+			require.Equal(t, tt.wantInTransition, IsFlexvmVMInTransition(err))
+			require.False(t, IsFlexvmVMTerminal(err))
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package fake provides an in-memory One API server for tests.
+//
+// The server keeps FlexMetal servers, tags, SSH keys, locations and quotas,
+// and FlexVM clouds, nodes and VMs in memory and serves them with the same
+// paths and payloads as the real API, so a one_api.Client (or the provider)
+// can be pointed at it with its base URL. Objects move through their
+// lifecycle on their own: a server goes from "requested" to "delivered", a VM
+// from "provisioning" to "running", and so on, each transition taking the
+// configured transition delay. Faults can be injected to test error handling.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+)
+
+// APIKey is the only API key accepted by the fake server.
+const APIKey = "fake-api-key"
+
+// Server is an in-memory One API. Create it with NewServer.
+type Server struct {
+	srv *httptest.Server
+
+	transitionDelay time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	faults    []*Fault
+	seq       int
+	servers   []*server
+	tags      []string
+	sshKeys   []*one_api.SSHKey
+	locations []one_api.Location
+	quotas    []Quota
+	clouds    []*cloud
+}
+
+// Option configures optional Server behaviour in NewServer.
+type Option func(*Server)
+
+// WithTransitionDelay sets how long every status transition takes, e.g. a VM
+// going from "provisioning" to "running". The default is zero: the new status
+// is visible from the next request on.
+func WithTransitionDelay(d time.Duration) Option {
+	return func(s *Server) {
+		s.transitionDelay = d
+	}
+}
+
+// WithLocations replaces the default locations served by the fake.
+func WithLocations(locations ...one_api.Location) Option {
+	return func(s *Server) {
+		s.locations = locations
+	}
+}
+
+// DefaultLocations are served unless WithLocations is passed.
+var DefaultLocations = []one_api.Location{
+	{ID: 6, Name: "EU: Rotterdam", ShortName: "EU-NL-01", DisplayName: "Rotterdam", CountryId: 152, CountryName: "Netherlands", CountryShortName: "NL"},
+	{ID: 33, Name: "NA: Montreal", ShortName: "NA-CA-01", DisplayName: "Montreal", CountryId: 38, CountryName: "Canada", CountryShortName: "CA"},
+}
+
+// NewServer starts a fake One API server. It is closed when the test ends.
+func NewServer(t testing.TB, opts ...Option) *Server {
+	s := &Server{
+		now:       time.Now,
+		locations: DefaultLocations,
+		quotas:    DefaultQuotas,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.srv = httptest.NewServer(s.handler())
+	t.Cleanup(s.srv.Close)
+
+	return s
+}
+
+// URL is the base URL to pass to one_api.NewClient or the provider base_url.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Client returns a one_api.Client for the fake server.
+func (s *Server) Client(opts ...one_api.Option) (*one_api.Client, error) {
+	return one_api.NewClient(APIKey, s.URL(), opts...)
+}
+
+// Fault makes the server fail matching requests.
+type Fault struct {
+	// Method matches the request method; empty matches any method.
+	Method string
+	// Path matches requests whose path starts with it, e.g.
+	// "/v3/flexVM/clouds". Empty matches any path.
+	Path string
+	// StatusCode, ErrorCode and ErrorMessage make up the error response.
+	StatusCode   int
+	ErrorCode    int
+	ErrorMessage string
+	// RetryAfter, when set, is sent as the Retry-After header in seconds.
+	RetryAfter int
+	// Times is the number of requests to fail, after which the fault is
+	// removed. Zero fails every matching request until ClearFaults.
+	Times int
+}
+
+// InjectFault makes the server fail the requests matching f. Faults are
+// checked in the order they were injected.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// matchFault returns the first fault matching r and consumes one of its
+// Times. The caller must hold s.mu.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return f
+	}
+
+	return nil
+}
+
+// handlerFunc handles a request with s.mu held.
+type handlerFunc func(w http.ResponseWriter, r *http.Request)
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	handle := func(pattern string, h handlerFunc) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if r.Header.Get("PRIVATE-TOKEN") != APIKey {
+				writeError(w, http.StatusForbidden, 0, "Invalid credentials")
+				return
+			}
+
+			if f := s.matchFault(r); f != nil {
+				if f.RetryAfter > 0 {
+					w.Header().Set("Retry-After", fmt.Sprint(f.RetryAfter))
+				}
+				writeError(w, f.StatusCode, f.ErrorCode, f.ErrorMessage)
+				return
+			}
+
+			h(w, r)
+		})
+	}
+
+	s.flexMetalRoutes(handle)
+	s.flexVMRoutes(handle)
+
+	return mux
+}
+
+// newID returns a unique, UUID formatted, ID. The caller must hold s.mu.
+func (s *Server) newID() string {
+	s.seq++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.seq)
+}
+
+// lifecycle tracks the status of an object and the transition it is going
+// through, if any.
+type lifecycle struct {
+	status string
+	next   string
+	at     time.Time
+}
+
+// set changes the status immediately and cancels any pending transition.
+func (l *lifecycle) set(status string) {
+	l.status = status
+	l.next = ""
+}
+
+// moveTo starts a transition: the status becomes to once delay has passed.
+func (l *lifecycle) moveTo(to string, now time.Time, delay time.Duration) {
+	l.next = to
+	l.at = now.Add(delay)
+}
+
+// advance completes the pending transition when it is due, and reports whether
+// it did.
+func (l *lifecycle) advance(now time.Time) bool {
+	if l.next == "" || now.Before(l.at) {
+		return false
+	}
+
+	l.status = l.next
+	l.next = ""
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status, errorCode int, message string) {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	writeJSON(w, status, one_api.APIError{ErrorCode: errorCode, ErrorMessage: message})
+}
+
+func writeValidationError(w http.ResponseWriter, property, message string) {
+	writeJSON(w, http.StatusUnprocessableEntity, one_api.APIError{
+		ErrorMessage: "Validation failed",
+		Errors:       []one_api.APIFieldError{{Property: property, Message: message}},
+	})
+}
+
+// writeRanged writes the page of items selected by the RANGED-DATA request
+// header, or every item when the header is absent, and describes the page in
+// the RANGED-DATA response header.
+func writeRanged[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	start, results := 0, len(items)
+	if v := r.Header.Get("RANGED-DATA"); v != "" {
+		if _, err := fmt.Sscanf(v, "start=%d,results=%d", &start, &results); err != nil || start < 0 || results < 0 {
+			writeError(w, http.StatusBadRequest, 0, "Invalid RANGED-DATA header")
+			return
+		}
+	}
+
+	start = min(start, len(items))
+	end := min(start+results, len(items))
+
+	w.Header().Set("RANGED-DATA", fmt.Sprintf("start=%d,results=%d,total=%d", start, end-start, len(items)))
+	writeJSON(w, http.StatusOK, append([]T{}, items[start:end]...))
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, 0, fmt.Sprintf("Invalid request body: %v", err))
+		return false
+	}
+	return true
+}
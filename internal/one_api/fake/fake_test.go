@@ -0,0 +1,345 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+	"terraform-provider-i3dnet/internal/one_api/fake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlexMetalServerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t).Client()
+	require.NoError(t, err)
+
+	server, err := c.CreateServer(ctx, one_api.CreateServerReq{
+		Name:         "web-1",
+		Location:     "EU: Rotterdam",
+		InstanceType: "bm7.std.8",
+		OS:           one_api.OS{Slug: "ubuntu-2404-lts"},
+		Tags:         []string{"web"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "requested", server.Status)
+
+	server, err = c.GetServer(ctx, server.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, "delivered", server.Status)
+	require.NotEmpty(t, server.IpAddresses)
+	require.NotZero(t, server.DeliveredAt)
+
+	_, err = c.AddTagToServer(ctx, server.Uuid, "prod")
+	require.NoError(t, err)
+
+	tag, err := c.GetTag(ctx, "prod")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), tag.Resources.FlexMetalServers.Count)
+
+	server, err = c.DeleteServer(ctx, server.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, "releasing", server.Status)
+
+	server, err = c.GetServer(ctx, server.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, "released", server.Status)
+
+	_, err = c.GetServer(ctx, "missing")
+	require.True(t, one_api.IsNotFound(err))
+
+	// Only Windows servers have a generated root password.
+	_, err = c.GetServerRootPassword(ctx, server.Uuid)
+	require.True(t, one_api.IsNotFound(err), "got %v", err)
+
+	windows, err := c.CreateServer(ctx, one_api.CreateServerReq{
+		Name:         "win-1",
+		Location:     "EU: Rotterdam",
+		InstanceType: "bm7.std.8",
+		OS:           one_api.OS{Slug: "windows-2022"},
+	})
+	require.NoError(t, err)
+
+	password, err := c.GetServerRootPassword(ctx, windows.Uuid)
+	require.NoError(t, err)
+	require.NotEmpty(t, password.RootPassword)
+
+	servers, err := c.ListServers(ctx, "", nil)
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+
+	servers, err = c.ListServers(ctx, "delivered", nil)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	require.Equal(t, "win-1", servers[0].Name)
+
+	servers, err = c.ListServers(ctx, "", []string{"web", "prod"})
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	require.Equal(t, "web-1", servers[0].Name)
+
+	servers, err = c.ListServers(ctx, "released", []string{"web", "staging"})
+	require.NoError(t, err)
+	require.Empty(t, servers)
+
+	operatingSystems, err := c.ListOperatingSystems(ctx)
+	require.NoError(t, err)
+	require.Equal(t, fake.OperatingSystems, operatingSystems)
+
+	// Inactive operating systems cannot be installed.
+	_, err = c.CreateServer(ctx, one_api.CreateServerReq{
+		Name:         "old-1",
+		Location:     "EU: Rotterdam",
+		InstanceType: "bm7.std.8",
+		OS:           one_api.OS{Slug: "ubuntu-2004-lts"},
+	})
+	require.ErrorContains(t, err, "not available for FlexMetal")
+}
+
+func TestFlexMetalQuotas(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t, fake.WithQuotas(
+		fake.Quota{Location: "EU: Rotterdam", InstanceType: "bm7.std.8", Quota: 1},
+		fake.Quota{Location: "EU: Rotterdam", InstanceType: "bm7.std.8", ContractID: "CONTRACT-123", Quota: 2},
+	)).Client()
+	require.NoError(t, err)
+
+	create := func(contractID string) error {
+		_, err := c.CreateServer(ctx, one_api.CreateServerReq{
+			Name:         "web",
+			Location:     "EU: Rotterdam",
+			InstanceType: "bm7.std.8",
+			OS:           one_api.OS{Slug: "ubuntu-2404-lts"},
+			ContractID:   contractID,
+		})
+		return err
+	}
+	require.NoError(t, create(""))
+	require.ErrorContains(t, create(""), "Quota of 1 bm7.std.8 servers in EU: Rotterdam exceeded")
+	require.NoError(t, create("CONTRACT-123"))
+
+	quotas, err := c.ListQuotaUsage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []one_api.ServerQuotaUsage{{
+		Location: one_api.LocationPartial{ID: 6, Name: "EU: Rotterdam"},
+		QuotaUsage: []one_api.QuotaUsage{
+			{InstanceType: one_api.InstanceTypePartial{ID: 101, Name: "bm7.std.8"}, QuotaType: one_api.QuotaTypeOnDemand, Quota: 1, Usage: 1},
+			{InstanceType: one_api.InstanceTypePartial{ID: 101, Name: "bm7.std.8"}, QuotaType: one_api.QuotaTypeCommit, ContractID: "CONTRACT-123", Quota: 2, Usage: 1},
+		},
+	}}, quotas)
+
+	commits, err := c.ListCapacityCommits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []one_api.CapacityCommit{{
+		Location: fake.DefaultLocations[0],
+		Commits:  []one_api.InstanceTypeCommit{{InstanceType: fake.LocationInstanceTypes[0], Capacity: 2, InUse: 1}},
+	}}, commits)
+}
+
+func TestFlexVMLifecycle(t *testing.T) {
+	t.Parallel()
+
+	const delay = 200 * time.Millisecond
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t, fake.WithTransitionDelay(delay)).Client()
+	require.NoError(t, err)
+
+	cloud, err := c.FlexvmCreateCloud(ctx, one_api.FlexvmCloudCreateRequest{
+		Name:         "cloud",
+		Site:         "frmtl1",
+		InstanceType: "bm7.std.8",
+	})
+	require.NoError(t, err)
+
+	node, err := c.FlexvmCreateNode(ctx, cloud.ID)
+	require.NoError(t, err)
+	require.Equal(t, "requested", node.Status)
+
+	time.Sleep(delay)
+
+	vm, err := c.FlexvmCreateVM(ctx, cloud.ID, one_api.FlexvmCreateVMRequest{
+		Name:             "vm",
+		InstanceTypeName: "vm.4c.8g",
+		ImageName:        "ubuntu-2404-server-amd64",
+		Tags:             []string{"env:test"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "provisioning", vm.Status)
+	require.Equal(t, []string{"env:test"}, vm.Tags)
+	require.NotNil(t, vm.Node)
+	require.Equal(t, node.ID, vm.Node.ID)
+
+	err = c.FlexvmDeleteVM(ctx, cloud.ID, vm.ID)
+	require.True(t, one_api.IsFlexvmVMInTransition(err), "got %v", err)
+
+	err = c.FlexvmDeleteNode(ctx, cloud.ID, node.ID)
+	require.True(t, one_api.IsConflict(err), "got %v", err)
+
+	time.Sleep(delay)
+
+	vm, err = c.FlexvmGetVM(ctx, cloud.ID, vm.ID)
+	require.NoError(t, err)
+	require.Equal(t, "running", vm.Status)
+
+	require.NoError(t, c.FlexvmExecuteCommand(ctx, cloud.ID, vm.ID, one_api.FlexvmCommandStop))
+
+	err = c.FlexvmExecuteCommand(ctx, cloud.ID, vm.ID, one_api.FlexvmCommandReboot)
+	require.Equal(t, http.StatusUnprocessableEntity, statusCode(err), "got %v", err)
+
+	time.Sleep(delay)
+
+	vm, err = c.FlexvmGetVM(ctx, cloud.ID, vm.ID)
+	require.NoError(t, err)
+	require.Equal(t, "stopped", vm.Status)
+
+	vms, err := c.FlexvmListVMs(ctx, cloud.ID, "stopped")
+	require.NoError(t, err)
+	require.Len(t, vms, 1)
+	vms, err = c.FlexvmListVMs(ctx, cloud.ID, "running")
+	require.NoError(t, err)
+	require.Empty(t, vms)
+
+	require.NoError(t, c.FlexvmDeleteVM(ctx, cloud.ID, vm.ID))
+
+	err = c.FlexvmDeleteVM(ctx, cloud.ID, vm.ID)
+	require.True(t, one_api.IsConflict(err), "got %v", err)
+
+	time.Sleep(delay)
+
+	vm, err = c.FlexvmGetVM(ctx, cloud.ID, vm.ID)
+	require.NoError(t, err)
+	require.Equal(t, "deleted", vm.Status)
+
+	err = c.FlexvmDeleteVM(ctx, cloud.ID, vm.ID)
+	require.True(t, one_api.IsFlexvmVMTerminal(err), "got %v", err)
+
+	require.NoError(t, c.FlexvmDeleteNode(ctx, cloud.ID, node.ID))
+	time.Sleep(delay)
+
+	require.NoError(t, c.FlexvmDeleteCloud(ctx, cloud.ID))
+
+	_, err = c.FlexvmGetCloud(ctx, cloud.ID)
+	require.True(t, one_api.IsNotFound(err), "got %v", err)
+}
+
+func TestFlexVMCapacityAndUsageReport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t).Client()
+	require.NoError(t, err)
+
+	cloud, err := c.FlexvmCreateCloud(ctx, one_api.FlexvmCloudCreateRequest{
+		Name:         "cloud",
+		Site:         "frmtl1",
+		InstanceType: "bm9.hmm.gpu.4rtx4000.64",
+	})
+	require.NoError(t, err)
+	_, err = c.FlexvmCreateNode(ctx, cloud.ID)
+	require.NoError(t, err)
+
+	_, err = c.FlexvmCreateVM(ctx, cloud.ID, one_api.FlexvmCreateVMRequest{
+		Name:             "gpu-vm",
+		InstanceTypeName: "vm.gpu.1rtx4000.15c.248g",
+		ImageName:        "ubuntu-2404-server-amd64",
+		Tags:             []string{"project:odyssey"},
+	})
+	require.NoError(t, err)
+
+	capacities, err := c.FlexvmListCloudCapacities(ctx)
+	require.NoError(t, err)
+	require.Len(t, capacities, 1)
+	require.Equal(t, cloud.ID, capacities[0].ID)
+	require.Equal(t, 1, capacities[0].Nodes)
+	require.Equal(t, one_api.FlexvmCapacityResource{Total: fake.NodeVCPU, InUse: 15, Available: fake.NodeVCPU - 15}, capacities[0].Resources.VCPU)
+	require.Equal(t, fake.NodeGPUs-1, capacities[0].Resources.PCIDevice("gpu").Available)
+
+	capacity, err := c.FlexvmGetCapacity(ctx)
+	require.NoError(t, err)
+	require.Equal(t, capacities[0].FlexvmCapacity, *capacity)
+
+	report, err := c.FlexvmGetUsageReport(ctx, "", "")
+	require.NoError(t, err)
+	require.Equal(t, 1, report.TotalNodes)
+	require.Equal(t, 1, report.TotalActiveVMs)
+	require.Len(t, report.Clouds, 1)
+	require.Equal(t, 1, report.Clouds[0].PeriodUsage.TotalVMsCreated)
+	require.Len(t, report.ByTag, 1)
+	require.Equal(t, "project:odyssey", report.ByTag[0].Tag)
+	require.Len(t, report.VMs, 1)
+	require.Equal(t, "vm.gpu.1rtx4000.15c.248g", report.VMs[0].Plan)
+
+	report, err = c.FlexvmGetUsageReport(ctx, "2020-01-01T00:00:00Z", "2020-02-01T00:00:00Z")
+	require.NoError(t, err)
+	require.Empty(t, report.VMs)
+
+	_, err = c.FlexvmGetUsageReport(ctx, "2020-01-01T00:00:00Z", "")
+	require.Equal(t, http.StatusBadRequest, statusCode(err), "got %v", err)
+}
+
+func TestFaultInjection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := fake.NewServer(t)
+	c, err := s.Client(one_api.WithRetry(one_api.RetryConfig{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: time.Millisecond}))
+	require.NoError(t, err)
+
+	// A transient failure is retried by the client.
+	s.InjectFault(fake.Fault{Method: http.MethodGet, Path: "/v3/flexMetal/location", StatusCode: http.StatusServiceUnavailable, Times: 2})
+	locations, err := c.ListLocations(ctx)
+	require.NoError(t, err)
+	require.Equal(t, fake.DefaultLocations, locations)
+
+	// A permanent failure is returned as an APIError.
+	s.InjectFault(fake.Fault{Path: "/v3/sshKey", StatusCode: http.StatusInternalServerError, ErrorMessage: "boom"})
+	_, err = c.ListSSHKeys(ctx)
+	require.ErrorContains(t, err, "boom")
+
+	s.ClearFaults()
+	_, err = c.ListSSHKeys(ctx)
+	require.NoError(t, err)
+
+	// Requests without the fake API key are rejected.
+	other, err := one_api.NewClient("wrong", s.URL())
+	require.NoError(t, err)
+	_, err = other.ListTags(ctx, "")
+	require.ErrorContains(t, err, "Invalid credentials")
+}
+
+func TestListsArePaged(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t).Client()
+	require.NoError(t, err)
+
+	for i := range 150 {
+		_, err := c.CreateSSHKey(ctx, one_api.CreateSSHKeyReq{Name: fmt.Sprintf("key-%d", i), PublicKey: "ssh-ed25519 AAAA"})
+		require.NoError(t, err)
+	}
+
+	keys, err := c.ListSSHKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 150)
+	require.Equal(t, "key-149", keys[149].Name)
+}
+
+// statusCode returns the status code of the APIError in err, or zero.
+func statusCode(err error) int {
+	var apiErr *one_api.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,521 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"terraform-provider-i3dnet/internal/one_api"
+)
+
+// server is a FlexMetal server. Server.Status mirrors lifecycle.status.
+type server struct {
+	one_api.Server
+	lifecycle
+	commands []*command
+	// rootPassword is generated on delivery of a Windows server.
+	rootPassword string
+}
+
+// command is an update-server command, created by an OS reinstall.
+type command struct {
+	one_api.Command
+	lifecycle
+}
+
+// LocationInstanceTypes are the FlexMetal instance types of DefaultLocations.
+var LocationInstanceTypes = []one_api.InstanceType{
+	{ID: 101, LocationID: 6, Name: "bm7.std.8", Sockets: 1, Cores: 8, Memory: 32, MemoryType: "DDR4", Storage: 480, StorageType: "SSD", GenerationName: "bm7"},
+	{ID: 102, LocationID: 6, Name: "bm9.std.16", Sockets: 1, Cores: 16, Memory: 64, MemoryType: "DDR5", Storage: 960, StorageType: "NVMe", GenerationName: "bm9"},
+	{ID: 103, LocationID: 33, Name: "bm7.std.8", Sockets: 1, Cores: 8, Memory: 32, MemoryType: "DDR4", Storage: 480, StorageType: "SSD", GenerationName: "bm7"},
+	{ID: 104, LocationID: 33, Name: "bm9.hmm.gpu.4rtx4000.64", Sockets: 2, Cores: 64, Memory: 1024, MemoryType: "DDR5", Storage: 7680, StorageType: "NVMe", GenerationName: "bm9"},
+}
+
+// OperatingSystems are the operating systems served by the fake. Only the
+// active ones available for FlexMetal can be installed on servers.
+var OperatingSystems = []one_api.OperatingSystem{
+	{ID: 1, Name: "Ubuntu 24.04 LTS", Slug: "ubuntu-2404-lts", OsGroup: one_api.OsGroupLinux, IsOdp: 1, AvailableForFlexMetal: 1, Active: 1},
+	{ID: 2, Name: "Ubuntu 20.04 LTS", Slug: "ubuntu-2004-lts", OsGroup: one_api.OsGroupLinux, IsOdp: 1, AvailableForFlexMetal: 1, Active: 0},
+	{ID: 3, Name: "Windows Server 2022", Slug: "windows-2022", OsGroup: one_api.OsGroupWindows, IsOdp: 1, AvailableForFlexMetal: 1, Active: 1},
+	{ID: 4, Name: "Windows Server 2019", Slug: "windows-2019", OsGroup: one_api.OsGroupWindows, IsOdp: 1, AvailableForFlexMetal: 0, Active: 1},
+	{ID: 5, Name: "Talos Omni 1.9.0", Slug: "talos-omni-190", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+	{ID: 6, Name: "Talos Omni 1.11.6", Slug: "talos-omni-1116", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+	{ID: 7, Name: "Talos Omni 1.12.3", Slug: "talos-omni-1123", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+	{ID: 8, Name: "Custom iPXE", Slug: "custom-ipxe", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+}
+
+func (s *Server) flexMetalRoutes(handle func(string, handlerFunc)) {
+	handle("GET /v3/flexMetal/servers", s.listServers)
+	handle("POST /v3/flexMetal/servers", s.createServer)
+	handle("GET /v3/flexMetal/servers/{uuid}", s.getServer)
+	handle("PATCH /v3/flexMetal/servers/{uuid}", s.reinstallServer)
+	handle("DELETE /v3/flexMetal/servers/{uuid}", s.releaseServer)
+	handle("GET /v3/flexMetal/servers/{uuid}/commands", s.listServerCommands)
+	handle("GET /v3/flexMetal/servers/{uuid}/rootPassword", s.getServerRootPassword)
+	handle("POST /v3/flexMetal/servers/{uuid}/tag/{tag}", s.addServerTag)
+	handle("DELETE /v3/flexMetal/servers/{uuid}/tag/{tag}", s.deleteServerTag)
+
+	handle("GET /v3/flexMetal/tags", s.listTags)
+	handle("POST /v3/flexMetal/tags", s.createTag)
+	handle("GET /v3/flexMetal/tags/{tag}", s.getTag)
+	handle("PUT /v3/flexMetal/tags/{tag}", s.updateTag)
+	handle("DELETE /v3/flexMetal/tags/{tag}", s.deleteTag)
+
+	handle("GET /v3/flexMetal/location", s.listLocations)
+	handle("GET /v3/flexMetal/location/{locationId}/instanceTypes", s.listLocationInstanceTypes)
+
+	handle("GET /v3/operatingsystem", s.listOperatingSystems)
+
+	s.flexMetalQuotaRoutes(handle)
+
+	handle("GET /v3/sshKey", s.listSSHKeys)
+	handle("POST /v3/sshKey", s.createSSHKey)
+	handle("GET /v3/sshKey/{uuid}", s.getSSHKey)
+	handle("DELETE /v3/sshKey/{uuid}", s.deleteSSHKey)
+}
+
+// SetServerStatus changes the status of a FlexMetal server, e.g. to "failed",
+// and cancels its pending transition. It reports whether the server exists.
+func (s *Server) SetServerStatus(uuid, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srv := s.findServer(uuid)
+	if srv == nil {
+		return false
+	}
+
+	srv.set(status)
+	srv.Status = status
+	return true
+}
+
+// findServer returns the server with the given uuid, with its pending
+// transitions applied. The caller must hold s.mu.
+func (s *Server) findServer(uuid string) *server {
+	for _, srv := range s.servers {
+		if srv.Uuid == uuid {
+			s.advanceServer(srv)
+			return srv
+		}
+	}
+	return nil
+}
+
+func (s *Server) advanceServer(srv *server) {
+	now := s.now()
+	if srv.advance(now) {
+		switch srv.lifecycle.status {
+		case "delivered":
+			srv.DeliveredAt = now.Unix()
+			if len(srv.IpAddresses) == 0 {
+				srv.IpAddresses = append(srv.IpAddresses, struct {
+					IpAddress string `json:"ipAddress"`
+				}{IpAddress: fmt.Sprintf("192.0.2.%d", s.seq%254+1)})
+			}
+			if strings.HasPrefix(srv.Os.Slug, "windows") {
+				srv.rootPassword = "fake-root-password-" + srv.Uuid[len(srv.Uuid)-4:]
+			}
+		case "released":
+			srv.ReleasedAt = now.Unix()
+		}
+	}
+	srv.Status = srv.lifecycle.status
+
+	for _, cmd := range srv.commands {
+		cmd.advance(now)
+		cmd.State = cmd.lifecycle.status
+	}
+}
+
+func (s *Server) listServers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	status, tags := query.Get("status"), query["tag"]
+
+	servers := make([]one_api.Server, 0, len(s.servers))
+	for _, srv := range s.servers {
+		s.advanceServer(srv)
+		if status != "" && srv.Status != status {
+			continue
+		}
+		if slices.ContainsFunc(tags, func(tag string) bool { return !slices.Contains(srv.Tags, tag) }) {
+			continue
+		}
+		servers = append(servers, srv.Server)
+	}
+
+	writeRanged(w, r, servers)
+}
+
+func (s *Server) createServer(w http.ResponseWriter, r *http.Request) {
+	var req one_api.CreateServerReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	switch {
+	case req.Name == "":
+		writeValidationError(w, "name", "name is required")
+		return
+	case req.Location == "":
+		writeValidationError(w, "location", "location is required")
+		return
+	case req.InstanceType == "":
+		writeValidationError(w, "instanceType", "instanceType is required")
+		return
+	case req.OS.Slug == "":
+		writeValidationError(w, "os.slug", "os.slug is required")
+		return
+	case !slices.ContainsFunc(OperatingSystems, func(os one_api.OperatingSystem) bool {
+		return os.Slug == req.OS.Slug && os.UsableForFlexMetal()
+	}):
+		writeValidationError(w, "os.slug", fmt.Sprintf("operating system %q is not available for FlexMetal", req.OS.Slug))
+		return
+	}
+
+	if q, ok := s.findQuota(req); ok && s.quotaUsage(q) >= q.Quota {
+		writeError(w, http.StatusUnprocessableEntity, 0,
+			fmt.Sprintf("Quota of %d %s servers in %s exceeded", q.Quota, req.InstanceType, req.Location))
+		return
+	}
+
+	srv := &server{}
+	srv.Uuid = s.newID()
+	srv.Name = req.Name
+	srv.Location.Name = req.Location
+	srv.InstanceType.Name = req.InstanceType
+	srv.Os.Slug = req.OS.Slug
+	srv.Tags = append([]string{}, req.Tags...)
+	srv.ContractID = req.ContractID
+	srv.CreatedAt = s.now().Unix()
+	srv.set("requested")
+	srv.moveTo("delivered", s.now(), s.transitionDelay)
+	srv.Status = srv.lifecycle.status
+
+	for _, tag := range req.Tags {
+		s.ensureTag(tag)
+	}
+
+	s.servers = append(s.servers, srv)
+	writeJSON(w, http.StatusOK, []one_api.Server{srv.Server})
+}
+
+func (s *Server) getServer(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil {
+		writeError(w, http.StatusNotFound, 0, "Server not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.Server{srv.Server})
+}
+
+func (s *Server) getServerRootPassword(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil || srv.rootPassword == "" {
+		writeError(w, http.StatusNotFound, 0, "Resource not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.ServerRootPassword{{RootPassword: srv.rootPassword}})
+}
+
+func (s *Server) reinstallServer(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil {
+		writeError(w, http.StatusNotFound, 0, "Server not found")
+		return
+	}
+
+	var req one_api.PatchServerReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if srv.Status != "delivered" {
+		writeError(w, http.StatusUnprocessableEntity, 0, fmt.Sprintf("Server is %s, it must be delivered to reinstall it", srv.Status))
+		return
+	}
+
+	if req.Name != "" {
+		srv.Name = req.Name
+	}
+	if req.Os.Slug != "" {
+		srv.Os.Slug = req.Os.Slug
+	}
+
+	cmd := &command{}
+	cmd.UUID = s.newID()
+	cmd.ServerUUID = srv.Uuid
+	cmd.CreatedAt = s.now().Format("2006-01-02T15:04:05Z07:00")
+	cmd.set("pending")
+	cmd.moveTo("finished", s.now(), s.transitionDelay)
+	cmd.State = cmd.lifecycle.status
+
+	// The most recent command comes first.
+	srv.commands = slices.Insert(srv.commands, 0, cmd)
+
+	writeJSON(w, http.StatusOK, []one_api.Server{srv.Server})
+}
+
+func (s *Server) releaseServer(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil {
+		writeError(w, http.StatusNotFound, 0, "Server not found")
+		return
+	}
+
+	switch srv.Status {
+	case "releasing", "released":
+	case "delivered":
+		srv.set("releasing")
+		srv.moveTo("released", s.now(), s.transitionDelay)
+		srv.Status = srv.lifecycle.status
+	default:
+		writeError(w, http.StatusUnprocessableEntity, 0, fmt.Sprintf("Server is %s and cannot be released", srv.Status))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.Server{srv.Server})
+}
+
+func (s *Server) listServerCommands(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil {
+		writeError(w, http.StatusNotFound, 0, "Server not found")
+		return
+	}
+
+	commands := []one_api.Command{}
+	if t := r.URL.Query().Get("type"); t == "" || t == "update-server" {
+		for _, cmd := range srv.commands {
+			commands = append(commands, cmd.Command)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, commands)
+}
+
+func (s *Server) addServerTag(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil {
+		writeError(w, http.StatusNotFound, 0, "Server not found")
+		return
+	}
+
+	tag := r.PathValue("tag")
+	s.ensureTag(tag)
+	if !slices.Contains(srv.Tags, tag) {
+		srv.Tags = append(srv.Tags, tag)
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.Server{srv.Server})
+}
+
+func (s *Server) deleteServerTag(w http.ResponseWriter, r *http.Request) {
+	srv := s.findServer(r.PathValue("uuid"))
+	if srv == nil {
+		writeError(w, http.StatusNotFound, 0, "Server not found")
+		return
+	}
+
+	tag := r.PathValue("tag")
+	i := slices.Index(srv.Tags, tag)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 0, "Tag not found on server")
+		return
+	}
+	srv.Tags = slices.Delete(srv.Tags, i, i+1)
+
+	writeJSON(w, http.StatusOK, []one_api.Server{srv.Server})
+}
+
+// ensureTag creates tag if it does not exist yet. The caller must hold s.mu.
+func (s *Server) ensureTag(tag string) {
+	if !slices.Contains(s.tags, tag) {
+		s.tags = append(s.tags, tag)
+	}
+}
+
+// tagObject returns the API representation of tag, counting the servers that
+// carry it. The caller must hold s.mu.
+func (s *Server) tagObject(tag string) one_api.Tag {
+	t := one_api.Tag{Tag: tag}
+	for _, srv := range s.servers {
+		if slices.Contains(srv.Tags, tag) {
+			t.Resources.FlexMetalServers.Count++
+		}
+	}
+	t.Resources.Count = t.Resources.FlexMetalServers.Count
+
+	return t
+}
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags := make([]one_api.Tag, 0, len(s.tags))
+	for _, tag := range s.tags {
+		tags = append(tags, s.tagObject(tag))
+	}
+
+	writeRanged(w, r, tags)
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request) {
+	var req one_api.Tag
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if req.Tag == "" {
+		writeValidationError(w, "tag", "tag is required")
+		return
+	}
+	if slices.Contains(s.tags, req.Tag) {
+		writeError(w, http.StatusUnprocessableEntity, 0, fmt.Sprintf("tag %s already exists", req.Tag))
+		return
+	}
+
+	s.tags = append(s.tags, req.Tag)
+	writeJSON(w, http.StatusOK, []one_api.Tag{s.tagObject(req.Tag)})
+}
+
+func (s *Server) getTag(w http.ResponseWriter, r *http.Request) {
+	tag := r.PathValue("tag")
+	if !slices.Contains(s.tags, tag) {
+		writeError(w, http.StatusNotFound, 0, "Tag not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.Tag{s.tagObject(tag)})
+}
+
+func (s *Server) updateTag(w http.ResponseWriter, r *http.Request) {
+	oldTag := r.PathValue("tag")
+	i := slices.Index(s.tags, oldTag)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 0, "Tag not found")
+		return
+	}
+
+	var req one_api.Tag
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if req.Tag == "" {
+		writeValidationError(w, "tag", "tag is required")
+		return
+	}
+	if req.Tag != oldTag && slices.Contains(s.tags, req.Tag) {
+		writeError(w, http.StatusConflict, 0, fmt.Sprintf("tag %s already exists", req.Tag))
+		return
+	}
+
+	s.tags[i] = req.Tag
+	for _, srv := range s.servers {
+		if j := slices.Index(srv.Tags, oldTag); j >= 0 {
+			srv.Tags[j] = req.Tag
+		}
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.Tag{s.tagObject(req.Tag)})
+}
+
+func (s *Server) deleteTag(w http.ResponseWriter, r *http.Request) {
+	tag := r.PathValue("tag")
+	i := slices.Index(s.tags, tag)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 0, "Tag not found")
+		return
+	}
+
+	s.tags = slices.Delete(s.tags, i, i+1)
+	for _, srv := range s.servers {
+		srv.Tags = slices.DeleteFunc(srv.Tags, func(t string) bool { return t == tag })
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listLocations(w http.ResponseWriter, r *http.Request) {
+	writeRanged(w, r, s.locations)
+}
+
+func (s *Server) listLocationInstanceTypes(w http.ResponseWriter, r *http.Request) {
+	locationID, err := strconv.Atoi(r.PathValue("locationId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 0, "Invalid location id")
+		return
+	}
+
+	instanceTypes := []one_api.InstanceType{}
+	for _, it := range LocationInstanceTypes {
+		if it.LocationID == locationID {
+			instanceTypes = append(instanceTypes, it)
+		}
+	}
+
+	writeRanged(w, r, instanceTypes)
+}
+
+func (s *Server) listOperatingSystems(w http.ResponseWriter, r *http.Request) {
+	writeRanged(w, r, OperatingSystems)
+}
+
+func (s *Server) listSSHKeys(w http.ResponseWriter, r *http.Request) {
+	keys := make([]one_api.SSHKey, 0, len(s.sshKeys))
+	for _, key := range s.sshKeys {
+		keys = append(keys, *key)
+	}
+
+	writeRanged(w, r, keys)
+}
+
+func (s *Server) createSSHKey(w http.ResponseWriter, r *http.Request) {
+	var req one_api.CreateSSHKeyReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	switch {
+	case req.Name == "":
+		writeValidationError(w, "name", "name is required")
+		return
+	case req.PublicKey == "":
+		writeValidationError(w, "publicKey", "publicKey is required")
+		return
+	}
+
+	key := &one_api.SSHKey{
+		Uuid:      s.newID(),
+		Name:      req.Name,
+		PublicKey: req.PublicKey,
+		CreatedAt: s.now().Unix(),
+	}
+	s.sshKeys = append(s.sshKeys, key)
+
+	writeJSON(w, http.StatusOK, []one_api.SSHKey{*key})
+}
+
+func (s *Server) getSSHKey(w http.ResponseWriter, r *http.Request) {
+	i := slices.IndexFunc(s.sshKeys, func(k *one_api.SSHKey) bool { return k.Uuid == r.PathValue("uuid") })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 0, "SSH key not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, []one_api.SSHKey{*s.sshKeys[i]})
+}
+
+func (s *Server) deleteSSHKey(w http.ResponseWriter, r *http.Request) {
+	i := slices.IndexFunc(s.sshKeys, func(k *one_api.SSHKey) bool { return k.Uuid == r.PathValue("uuid") })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 0, "SSH key not found")
+		return
+	}
+
+	s.sshKeys = slices.Delete(s.sshKeys, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package fake
+
+import (
+	"net/http"
+	"slices"
+
+	"terraform-provider-i3dnet/internal/one_api"
+)
+
+// Quota is the number of FlexMetal servers of an instance type that can be
+// ordered in a location, on demand or under a contract.
+type Quota struct {
+	Location     string
+	InstanceType string
+	// ContractID is empty for the on demand quota.
+	ContractID string
+	Quota      int
+}
+
+// WithQuotas replaces the default quotas of the fake.
+func WithQuotas(quotas ...Quota) Option {
+	return func(s *Server) {
+		s.quotas = quotas
+	}
+}
+
+// DefaultQuotas are used unless WithQuotas is passed. Servers can only be
+// created within these quotas.
+var DefaultQuotas = []Quota{
+	{Location: "EU: Rotterdam", InstanceType: "bm7.std.8", Quota: 5},
+	{Location: "EU: Rotterdam", InstanceType: "bm9.std.16", Quota: 2},
+	{Location: "EU: Rotterdam", InstanceType: "bm7.std.8", ContractID: "CONTRACT-123", Quota: 2},
+	{Location: "NA: Montreal", InstanceType: "bm7.std.8", Quota: 5},
+	{Location: "NA: Montreal", InstanceType: "bm9.hmm.gpu.4rtx4000.64", Quota: 1},
+}
+
+func (s *Server) flexMetalQuotaRoutes(handle func(string, handlerFunc)) {
+	handle("GET /v3/flexMetal/quota/usage", s.listQuotaUsage)
+	handle("GET /v3/flexMetal/capacity/commit", s.listCapacityCommits)
+}
+
+func (s *Server) listQuotaUsage(w http.ResponseWriter, r *http.Request) {
+	quotas := []one_api.ServerQuotaUsage{}
+	for _, l := range s.locations {
+		locationQuotas := one_api.ServerQuotaUsage{
+			Location:   one_api.LocationPartial{ID: l.ID, Name: l.Name},
+			QuotaUsage: []one_api.QuotaUsage{},
+		}
+		for _, q := range s.quotas {
+			if q.Location != l.Name {
+				continue
+			}
+			quotaType := one_api.QuotaTypeOnDemand
+			if q.ContractID != "" {
+				quotaType = one_api.QuotaTypeCommit
+			}
+			locationQuotas.QuotaUsage = append(locationQuotas.QuotaUsage, one_api.QuotaUsage{
+				InstanceType: one_api.InstanceTypePartial{ID: instanceTypeID(l.ID, q.InstanceType), Name: q.InstanceType},
+				QuotaType:    quotaType,
+				ContractID:   q.ContractID,
+				Quota:        q.Quota,
+				Usage:        s.quotaUsage(q),
+			})
+		}
+		if len(locationQuotas.QuotaUsage) > 0 {
+			quotas = append(quotas, locationQuotas)
+		}
+	}
+
+	writeRanged(w, r, quotas)
+}
+
+func (s *Server) listCapacityCommits(w http.ResponseWriter, r *http.Request) {
+	commits := []one_api.CapacityCommit{}
+	for _, l := range s.locations {
+		locationCommits := one_api.CapacityCommit{Location: l, Commits: []one_api.InstanceTypeCommit{}}
+		for _, q := range s.quotas {
+			if q.Location != l.Name || q.ContractID == "" {
+				continue
+			}
+
+			// The commits of all contracts of an instance type are summed.
+			i := slices.IndexFunc(locationCommits.Commits, func(c one_api.InstanceTypeCommit) bool {
+				return c.InstanceType.Name == q.InstanceType
+			})
+			if i < 0 {
+				instanceType := one_api.InstanceType{ID: instanceTypeID(l.ID, q.InstanceType), LocationID: l.ID, Name: q.InstanceType}
+				if j := slices.IndexFunc(LocationInstanceTypes, func(it one_api.InstanceType) bool {
+					return it.LocationID == l.ID && it.Name == q.InstanceType
+				}); j >= 0 {
+					instanceType = LocationInstanceTypes[j]
+				}
+				locationCommits.Commits = append(locationCommits.Commits, one_api.InstanceTypeCommit{InstanceType: instanceType})
+				i = len(locationCommits.Commits) - 1
+			}
+			locationCommits.Commits[i].Capacity += q.Quota
+			locationCommits.Commits[i].InUse += s.quotaUsage(q)
+		}
+		if len(locationCommits.Commits) > 0 {
+			commits = append(commits, locationCommits)
+		}
+	}
+
+	writeRanged(w, r, commits)
+}
+
+// quotaUsage returns the number of servers counting towards q: the servers
+// that are not released, in its location, of its instance type and ordered
+// under its contract. The caller must hold s.mu.
+func (s *Server) quotaUsage(q Quota) int {
+	usage := 0
+	for _, srv := range s.servers {
+		s.advanceServer(srv)
+		if srv.Status != "released" && srv.Location.Name == q.Location && srv.InstanceType.Name == q.InstanceType &&
+			srv.ContractID == q.ContractID {
+			usage++
+		}
+	}
+	return usage
+}
+
+// findQuota returns the quota a new server ordered with req counts towards.
+func (s *Server) findQuota(req one_api.CreateServerReq) (Quota, bool) {
+	i := slices.IndexFunc(s.quotas, func(q Quota) bool {
+		return q.Location == req.Location && q.InstanceType == req.InstanceType && q.ContractID == req.ContractID
+	})
+	if i < 0 {
+		return Quota{}, false
+	}
+	return s.quotas[i], true
+}
+
+// instanceTypeID returns the ID of the instance type named name in the
+// location locationID, or 0 when the location has no such instance type.
+func instanceTypeID(locationID int, name string) int {
+	i := slices.IndexFunc(LocationInstanceTypes, func(it one_api.InstanceType) bool {
+		return it.LocationID == locationID && it.Name == name
+	})
+	if i < 0 {
+		return 0
+	}
+	return LocationInstanceTypes[i].ID
+}
@@ -0,0 +1,517 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+)
+
+// Sites are the FlexVM sites accepted when creating a cloud.
+var Sites = []string{"frmtl1", "camtr6"}
+
+// Images are the FlexVM images served by the fake and accepted when creating
+// a VM.
+var Images = []one_api.FlexvmImage{
+	{Name: "ubuntu-2204-server-amd64", OS: "Ubuntu LTS 22.04", OSType: "linux"},
+	{Name: "ubuntu-2404-server-amd64", OS: "Ubuntu LTS 24.04", OSType: "linux"},
+	{Name: "debian-12-server-amd64", OS: "Debian 12", OSType: "linux"},
+	{Name: "windows-2022-server-amd64", OS: "Windows Server 2022", OSType: "windows"},
+}
+
+// InstanceTypes are the FlexVM instance types served by the fake and accepted
+// when creating a VM.
+var InstanceTypes = []one_api.FlexvmInstanceType{
+	{ID: "00000000-0000-4000-9000-000000000001", Name: "vm.2c.4g", VCPU: 2, Memory: 4096, Disk: 40},
+	{ID: "00000000-0000-4000-9000-000000000002", Name: "vm.4c.8g", VCPU: 4, Memory: 8192, Disk: 80},
+	{ID: "00000000-0000-4000-9000-000000000003", Name: "vm.8c.16g", VCPU: 8, Memory: 16384, Disk: 160},
+	{
+		ID: "00000000-0000-4000-9000-000000000004", Name: "vm.gpu.1rtx4000.15c.248g", VCPU: 15, Memory: 253952, Disk: 256,
+		PCIDevices: []one_api.FlexvmPCIDevice{{Type: "gpu", Spec: "rtx4000"}},
+	},
+}
+
+type cloud struct {
+	one_api.FlexvmCloudObj
+	nodes []*node
+	vms   []*vm
+}
+
+// node is a FlexVM node. FlexvmNodeObj.Status mirrors lifecycle.status.
+type node struct {
+	one_api.FlexvmNodeObj
+	lifecycle
+}
+
+// vm is a FlexVM VM. FlexvmVM.Status mirrors lifecycle.status.
+type vm struct {
+	one_api.FlexvmVM
+	lifecycle
+	nodeID string
+}
+
+func (s *Server) flexVMRoutes(handle func(string, handlerFunc)) {
+	s.flexVMReportRoutes(handle)
+
+	handle("GET /v3/flexVM/images", s.listImages)
+	handle("GET /v3/flexVM/instanceTypes", s.listInstanceTypes)
+
+	handle("GET /v3/flexVM/clouds", s.listClouds)
+	handle("POST /v3/flexVM/clouds", s.createCloud)
+	handle("GET /v3/flexVM/clouds/{cloud}", s.withCloud(s.getCloud))
+	handle("DELETE /v3/flexVM/clouds/{cloud}", s.withCloud(s.deleteCloud))
+
+	handle("GET /v3/flexVM/clouds/{cloud}/nodes", s.withCloud(s.listNodes))
+	handle("POST /v3/flexVM/clouds/{cloud}/nodes", s.withCloud(s.createNode))
+	handle("GET /v3/flexVM/clouds/{cloud}/nodes/{node}", s.withCloud(s.getNode))
+	handle("DELETE /v3/flexVM/clouds/{cloud}/nodes/{node}", s.withCloud(s.deleteNode))
+
+	handle("GET /v3/flexVM/clouds/{cloud}/vms", s.withCloud(s.listVMs))
+	handle("POST /v3/flexVM/clouds/{cloud}/vms", s.withCloud(s.createVM))
+	handle("GET /v3/flexVM/clouds/{cloud}/vms/{vm}", s.withCloud(s.getVM))
+	handle("DELETE /v3/flexVM/clouds/{cloud}/vms/{vm}", s.withCloud(s.deleteVM))
+	handle("POST /v3/flexVM/clouds/{cloud}/vms/{vm}/commands", s.withCloud(s.executeVMCommand))
+}
+
+// SetNodeStatus changes the status of a FlexVM node, e.g. to "failed", and
+// cancels its pending transition. It reports whether the node exists.
+func (s *Server) SetNodeStatus(cloudID, nodeID, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCloud(cloudID)
+	if c == nil {
+		return false
+	}
+	n := s.findNode(c, nodeID)
+	if n == nil {
+		return false
+	}
+
+	n.set(status)
+	n.Status = status
+	return true
+}
+
+// SetVMStatus changes the status of a FlexVM VM, e.g. to "stopping", and
+// cancels its pending transition. It reports whether the VM exists.
+func (s *Server) SetVMStatus(cloudID, vmID, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCloud(cloudID)
+	if c == nil {
+		return false
+	}
+	v := s.findVM(c, vmID)
+	if v == nil {
+		return false
+	}
+
+	v.set(status)
+	v.Status = status
+	return true
+}
+
+// MoveVMStatus makes a FlexVM VM go through status, e.g. "stopping", and then
+// reach to after the transition delay. It reports whether the VM exists.
+func (s *Server) MoveVMStatus(cloudID, vmID, status, to string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCloud(cloudID)
+	if c == nil {
+		return false
+	}
+	v := s.findVM(c, vmID)
+	if v == nil {
+		return false
+	}
+
+	v.set(status)
+	v.moveTo(to, s.now(), s.transitionDelay)
+	v.Status = status
+	return true
+}
+
+// withCloud looks up the cloud in the request path and passes it to h, or
+// responds with a 404.
+func (s *Server) withCloud(h func(http.ResponseWriter, *http.Request, *cloud)) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := s.findCloud(r.PathValue("cloud"))
+		if c == nil {
+			writeError(w, http.StatusNotFound, 0, "Cloud not found")
+			return
+		}
+		h(w, r, c)
+	}
+}
+
+// findCloud returns the cloud with the given ID. The caller must hold s.mu.
+func (s *Server) findCloud(id string) *cloud {
+	for _, c := range s.clouds {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// findNode returns the node with the given ID, with its pending transition
+// applied. The caller must hold s.mu.
+func (s *Server) findNode(c *cloud, id string) *node {
+	for _, n := range c.nodes {
+		if n.ID == id {
+			s.advanceNode(n)
+			return n
+		}
+	}
+	return nil
+}
+
+// findVM returns the VM with the given ID, with its pending transition
+// applied. The caller must hold s.mu.
+func (s *Server) findVM(c *cloud, id string) *vm {
+	for _, v := range c.vms {
+		if v.ID == id {
+			s.advanceVM(v)
+			return v
+		}
+	}
+	return nil
+}
+
+func (s *Server) advanceNode(n *node) {
+	n.advance(s.now())
+	n.Status = n.lifecycle.status
+}
+
+func (s *Server) advanceVM(v *vm) {
+	v.advance(s.now())
+	v.Status = v.lifecycle.status
+	if v.Status == "deleted" && v.DeletedAt == "" {
+		v.DeletedAt = s.now().Format(time.RFC3339)
+	}
+}
+
+func (s *Server) listImages(w http.ResponseWriter, r *http.Request) {
+	writeRanged(w, r, Images)
+}
+
+func (s *Server) listInstanceTypes(w http.ResponseWriter, r *http.Request) {
+	writeRanged(w, r, InstanceTypes)
+}
+
+func (s *Server) listClouds(w http.ResponseWriter, r *http.Request) {
+	clouds := make([]one_api.FlexvmCloudObj, 0, len(s.clouds))
+	for _, c := range s.clouds {
+		clouds = append(clouds, c.FlexvmCloudObj)
+	}
+
+	writeRanged(w, r, clouds)
+}
+
+func (s *Server) createCloud(w http.ResponseWriter, r *http.Request) {
+	var req one_api.FlexvmCloudCreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	switch {
+	case req.Name == "":
+		writeValidationError(w, "name", "name is required")
+		return
+	case !slices.Contains(Sites, req.Site):
+		writeValidationError(w, "site", fmt.Sprintf("site must be one of %v", Sites))
+		return
+	case req.InstanceType == "":
+		writeValidationError(w, "instance_type", "instance_type is required")
+		return
+	}
+
+	for _, c := range s.clouds {
+		if c.Name == req.Name {
+			writeError(w, http.StatusConflict, 0, fmt.Sprintf("Cloud %s already exists", req.Name))
+			return
+		}
+	}
+
+	c := &cloud{FlexvmCloudObj: one_api.FlexvmCloudObj{
+		ID:           s.newID(),
+		Name:         req.Name,
+		Site:         req.Site,
+		InstanceType: req.InstanceType,
+		Description:  req.Description,
+		CreatedAt:    s.now().Format(time.RFC3339),
+	}}
+	s.clouds = append(s.clouds, c)
+
+	writeJSON(w, http.StatusCreated, c.FlexvmCloudObj)
+}
+
+func (s *Server) getCloud(w http.ResponseWriter, r *http.Request, c *cloud) {
+	writeJSON(w, http.StatusOK, c.FlexvmCloudObj)
+}
+
+func (s *Server) deleteCloud(w http.ResponseWriter, r *http.Request, c *cloud) {
+	for _, n := range c.nodes {
+		if s.advanceNode(n); n.Status != "deleted" {
+			writeError(w, http.StatusUnprocessableEntity, 0, "Cloud still has nodes")
+			return
+		}
+	}
+	for _, v := range c.vms {
+		if s.advanceVM(v); v.Status != "deleted" {
+			writeError(w, http.StatusUnprocessableEntity, 0, "Cloud still has VMs")
+			return
+		}
+	}
+
+	s.clouds = slices.DeleteFunc(s.clouds, func(other *cloud) bool { return other == c })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listNodes(w http.ResponseWriter, r *http.Request, c *cloud) {
+	nodes := make([]one_api.FlexvmNodeObj, 0, len(c.nodes))
+	for _, n := range c.nodes {
+		s.advanceNode(n)
+		nodes = append(nodes, n.FlexvmNodeObj)
+	}
+
+	writeRanged(w, r, nodes)
+}
+
+func (s *Server) createNode(w http.ResponseWriter, r *http.Request, c *cloud) {
+	cloudObj := c.FlexvmCloudObj
+
+	n := &node{}
+	n.ID = s.newID()
+	n.Name = fmt.Sprintf("%s-node-%d", c.Name, len(c.nodes)+1)
+	n.Serial = fmt.Sprintf("SN%08d", s.seq)
+	n.Cloud = &cloudObj
+	n.set("requested")
+	n.moveTo("running", s.now(), s.transitionDelay)
+	n.Status = n.lifecycle.status
+	c.nodes = append(c.nodes, n)
+
+	writeJSON(w, http.StatusCreated, n.FlexvmNodeObj)
+}
+
+func (s *Server) getNode(w http.ResponseWriter, r *http.Request, c *cloud) {
+	n := s.findNode(c, r.PathValue("node"))
+	if n == nil {
+		writeError(w, http.StatusNotFound, 0, "Node not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n.FlexvmNodeObj)
+}
+
+func (s *Server) deleteNode(w http.ResponseWriter, r *http.Request, c *cloud) {
+	n := s.findNode(c, r.PathValue("node"))
+	if n == nil {
+		writeError(w, http.StatusNotFound, 0, "Node not found")
+		return
+	}
+
+	switch n.Status {
+	case "deleting", "deleted":
+		writeError(w, http.StatusConflict, 0, fmt.Sprintf("Node is already %s", n.Status))
+		return
+	case "running", "failed":
+	default:
+		writeError(w, http.StatusUnprocessableEntity, 0, fmt.Sprintf("Node is %s and cannot be deleted", n.Status))
+		return
+	}
+
+	for _, v := range c.vms {
+		if s.advanceVM(v); v.nodeID == n.ID && v.Status != "deleted" && v.Status != "failed" {
+			writeError(w, http.StatusConflict, 0, "Node still has VMs")
+			return
+		}
+	}
+
+	n.set("deleting")
+	n.moveTo("deleted", s.now(), s.transitionDelay)
+	n.Status = n.lifecycle.status
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) listVMs(w http.ResponseWriter, r *http.Request, c *cloud) {
+	status := r.URL.Query().Get("status")
+
+	vms := make([]one_api.FlexvmVM, 0, len(c.vms))
+	for _, v := range c.vms {
+		s.advanceVM(v)
+		if status == "" || v.Status == status {
+			vms = append(vms, v.FlexvmVM)
+		}
+	}
+
+	writeRanged(w, r, vms)
+}
+
+func (s *Server) createVM(w http.ResponseWriter, r *http.Request, c *cloud) {
+	var req one_api.FlexvmCreateVMRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	switch {
+	case req.Name == "":
+		writeValidationError(w, "name", "name is required")
+		return
+	case req.InstanceTypeName == "":
+		writeValidationError(w, "instance_type_name", "instance_type_name is required")
+		return
+	case req.ImageName == "":
+		writeValidationError(w, "image_name", "image_name is required")
+		return
+	case len(req.SSHKeys) > 0 && req.UserData != nil:
+		writeValidationError(w, "user_data", "ssh_keys and user_data are mutually exclusive")
+		return
+	}
+
+	imageIdx := slices.IndexFunc(Images, func(i one_api.FlexvmImage) bool { return i.Name == req.ImageName })
+	if imageIdx < 0 {
+		writeValidationError(w, "image_name", fmt.Sprintf("image %q does not exist", req.ImageName))
+		return
+	}
+	instanceTypeIdx := slices.IndexFunc(InstanceTypes, func(t one_api.FlexvmInstanceType) bool { return t.Name == req.InstanceTypeName })
+	if instanceTypeIdx < 0 {
+		writeValidationError(w, "instance_type_name", fmt.Sprintf("instance type %q does not exist", req.InstanceTypeName))
+		return
+	}
+
+	v := &vm{}
+	v.ID = s.newID()
+	v.Name = req.Name
+	v.Description = req.Description
+	v.InstanceType = InstanceTypes[instanceTypeIdx]
+	v.InstanceType.PCIDevices = nil
+	v.Image = Images[imageIdx]
+	v.Image.ID = fmt.Sprintf("00000000-0000-4000-a000-%012d", imageIdx+1)
+	v.IPs = []one_api.FlexvmIPAddress{{Address: fmt.Sprintf("198.51.100.%d", s.seq%254+1), Public: true}}
+	v.Cloud = one_api.FlexvmCloud{ID: c.ID, Name: c.Name, Description: c.Description, Site: c.Site}
+	v.CreatedAt = s.now().Format(time.RFC3339)
+	v.Tags = append([]string{}, req.Tags...)
+
+	if n := s.leastLoadedNode(c); n != nil {
+		v.nodeID = n.ID
+		v.Node = &one_api.FlexvmNode{ID: n.ID, Name: n.Name, InstanceType: c.InstanceType, Serial: n.Serial, Cloud: v.Cloud}
+	}
+
+	v.set("provisioning")
+	v.moveTo("running", s.now(), s.transitionDelay)
+	v.Status = v.lifecycle.status
+	c.vms = append(c.vms, v)
+
+	writeJSON(w, http.StatusCreated, v.FlexvmVM)
+}
+
+// leastLoadedNode returns the running node of c with the fewest VMs, or nil
+// when c has no running node. The caller must hold s.mu.
+func (s *Server) leastLoadedNode(c *cloud) *node {
+	var best *node
+	bestVMs := 0
+
+	for _, n := range c.nodes {
+		if s.advanceNode(n); n.Status != "running" {
+			continue
+		}
+
+		vms := 0
+		for _, v := range c.vms {
+			if v.nodeID == n.ID && v.Status != "deleted" {
+				vms++
+			}
+		}
+
+		if best == nil || vms < bestVMs {
+			best, bestVMs = n, vms
+		}
+	}
+
+	return best
+}
+
+func (s *Server) getVM(w http.ResponseWriter, r *http.Request, c *cloud) {
+	v := s.findVM(c, r.PathValue("vm"))
+	if v == nil {
+		writeError(w, http.StatusNotFound, 0, "VM not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v.FlexvmVM)
+}
+
+func (s *Server) deleteVM(w http.ResponseWriter, r *http.Request, c *cloud) {
+	v := s.findVM(c, r.PathValue("vm"))
+	if v == nil {
+		writeError(w, http.StatusNotFound, 0, "VM not found")
+		return
+	}
+
+	switch v.Status {
+	case "deleting":
+		writeError(w, http.StatusConflict, 0, "VM is already being deleted")
+		return
+	case "failed", "deleted":
+		writeError(w, http.StatusUnprocessableEntity, one_api.FlexvmErrCodeVMTerminal, fmt.Sprintf("VM is %s", v.Status))
+		return
+	case "running", "stopped":
+	default:
+		writeError(w, http.StatusUnprocessableEntity, one_api.FlexvmErrCodeVMInTransition, fmt.Sprintf("VM is %s", v.Status))
+		return
+	}
+
+	v.set("deleting")
+	v.moveTo("deleted", s.now(), s.transitionDelay)
+	v.Status = v.lifecycle.status
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// executeVMCommand starts the transition of a power command: "start" moves a
+// stopped VM through "starting" to "running", "stop" a running VM through
+// "stopping" to "stopped", and "reboot" and "reset" a running VM through
+// "starting" back to "running".
+func (s *Server) executeVMCommand(w http.ResponseWriter, r *http.Request, c *cloud) {
+	v := s.findVM(c, r.PathValue("vm"))
+	if v == nil {
+		writeError(w, http.StatusNotFound, 0, "VM not found")
+		return
+	}
+
+	var req one_api.FlexvmVMCommandRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	var from, via, to string
+	switch req.Command {
+	case one_api.FlexvmCommandStart:
+		from, via, to = "stopped", "starting", "running"
+	case one_api.FlexvmCommandStop:
+		from, via, to = "running", "stopping", "stopped"
+	case one_api.FlexvmCommandReboot, one_api.FlexvmCommandReset:
+		from, via, to = "running", "starting", "running"
+	default:
+		writeValidationError(w, "command", "command must be one of: start, stop, reboot, reset")
+		return
+	}
+
+	if v.Status != from {
+		writeError(w, http.StatusUnprocessableEntity, 0, fmt.Sprintf("VM is %s, it must be %s to %s it", v.Status, from, req.Command))
+		return
+	}
+
+	v.set(via)
+	v.moveTo(to, s.now(), s.transitionDelay)
+	v.Status = v.lifecycle.status
+
+	w.WriteHeader(http.StatusAccepted)
+}
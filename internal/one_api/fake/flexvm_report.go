@@ -0,0 +1,261 @@
+package fake
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+)
+
+// Resources each running FlexVM node offers to VMs, whatever the instance
+// type of its cloud.
+const (
+	NodeVCPU   = 64
+	NodeMemory = 512 * 1024
+	NodeGPUs   = 4
+)
+
+func (s *Server) flexVMReportRoutes(handle func(string, handlerFunc)) {
+	handle("GET /v3/flexVM/capacity", s.listCloudCapacities)
+	handle("GET /v3/flexVM/reports/capacity", s.getCapacity)
+	handle("GET /v3/flexVM/reports/monthly", s.getUsageReport)
+}
+
+func (s *Server) listCloudCapacities(w http.ResponseWriter, r *http.Request) {
+	capacities := make([]one_api.FlexvmCloudCapacity, 0, len(s.clouds))
+	for _, c := range s.clouds {
+		capacities = append(capacities, one_api.FlexvmCloudCapacity{
+			FlexvmCloudObj: c.FlexvmCloudObj,
+			FlexvmCapacity: s.cloudCapacity(c),
+		})
+	}
+
+	writeRanged(w, r, capacities)
+}
+
+func (s *Server) getCapacity(w http.ResponseWriter, r *http.Request) {
+	total := one_api.FlexvmCapacity{NodeGroups: []one_api.FlexvmCapacityNodeGroup{}}
+	gpus := one_api.FlexvmCapacityResource{}
+
+	for _, c := range s.clouds {
+		capacity := s.cloudCapacity(c)
+		total.Nodes += capacity.Nodes
+		for _, g := range capacity.NodeGroups {
+			i := slices.IndexFunc(total.NodeGroups, func(tg one_api.FlexvmCapacityNodeGroup) bool {
+				return tg.InstanceType == g.InstanceType
+			})
+			if i < 0 {
+				total.NodeGroups = append(total.NodeGroups, g)
+			} else {
+				total.NodeGroups[i].Quantity += g.Quantity
+			}
+		}
+		addResource(&total.Resources.VCPU, capacity.Resources.VCPU)
+		addResource(&total.Resources.Memory, capacity.Resources.Memory)
+		addResource(&gpus, capacity.Resources.PCIDevice("gpu"))
+	}
+	total.Resources.PCIDevices = []one_api.FlexvmCapacityPCIDevice{{Type: "gpu", FlexvmCapacityResource: gpus}}
+
+	writeJSON(w, http.StatusOK, total)
+}
+
+// cloudCapacity computes the capacity of c: running nodes offer resources,
+// and VMs that are not deleted use them. The caller must hold s.mu.
+func (s *Server) cloudCapacity(c *cloud) one_api.FlexvmCapacity {
+	capacity := one_api.FlexvmCapacity{NodeGroups: []one_api.FlexvmCapacityNodeGroup{}}
+
+	running := 0
+	for _, n := range c.nodes {
+		if s.advanceNode(n); n.Status == "deleted" {
+			continue
+		}
+		capacity.Nodes++
+		if n.Status == "running" {
+			running++
+		}
+	}
+	if capacity.Nodes > 0 {
+		capacity.NodeGroups = append(capacity.NodeGroups, one_api.FlexvmCapacityNodeGroup{
+			InstanceType: c.InstanceType,
+			Quantity:     capacity.Nodes,
+		})
+	}
+
+	var vcpu, memory, gpus int
+	for _, v := range c.vms {
+		if s.advanceVM(v); v.Status == "deleted" {
+			continue
+		}
+		vcpu += v.InstanceType.VCPU
+		memory += v.InstanceType.Memory
+		gpus += vmGPUs(v)
+	}
+
+	capacity.Resources = one_api.FlexvmCapacityResources{
+		VCPU:   usage(running*NodeVCPU, vcpu),
+		Memory: usage(running*NodeMemory, memory),
+		PCIDevices: []one_api.FlexvmCapacityPCIDevice{
+			{Type: "gpu", FlexvmCapacityResource: usage(running*NodeGPUs, gpus)},
+		},
+	}
+
+	return capacity
+}
+
+// vmGPUs returns the number of GPUs required by the instance type of v.
+func vmGPUs(v *vm) int {
+	i := slices.IndexFunc(InstanceTypes, func(t one_api.FlexvmInstanceType) bool { return t.Name == v.InstanceType.Name })
+	if i < 0 {
+		return 0
+	}
+
+	gpus := 0
+	for _, d := range InstanceTypes[i].PCIDevices {
+		if d.Type == "gpu" {
+			gpus++
+		}
+	}
+	return gpus
+}
+
+func usage(total, inUse int) one_api.FlexvmCapacityResource {
+	inUse = min(inUse, total)
+	return one_api.FlexvmCapacityResource{Total: total, InUse: inUse, Available: total - inUse}
+}
+
+func addResource(sum *one_api.FlexvmCapacityResource, r one_api.FlexvmCapacityResource) {
+	sum.Total += r.Total
+	sum.InUse += r.InUse
+	sum.Available += r.Available
+}
+
+func (s *Server) getUsageReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, end := query.Get("start"), query.Get("end")
+	if (start == "") != (end == "") {
+		writeError(w, http.StatusBadRequest, 0, "start and end must be provided together")
+		return
+	}
+
+	var from, to time.Time
+	if start == "" {
+		now := s.now().UTC()
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 1, 0)
+	} else {
+		var errStart, errEnd error
+		from, errStart = time.Parse(time.RFC3339, start)
+		to, errEnd = time.Parse(time.RFC3339, end)
+		if errStart != nil || errEnd != nil || !from.Before(to) {
+			writeError(w, http.StatusBadRequest, 0, "Invalid reporting period")
+			return
+		}
+	}
+
+	report := one_api.FlexvmUsageReport{
+		Period: one_api.FlexvmUsageReportPeriod{Start: from.Format(time.RFC3339), End: to.Format(time.RFC3339)},
+		Clouds: []one_api.FlexvmUsageReportCloud{},
+		ByTag:  []one_api.FlexvmUsageReportTag{},
+		VMs:    []one_api.FlexvmUsageReportVM{},
+	}
+
+	for _, c := range s.clouds {
+		reportCloud := one_api.FlexvmUsageReportCloud{ID: c.ID, Site: c.Site, InstanceType: c.InstanceType}
+		for _, n := range c.nodes {
+			if s.advanceNode(n); n.Status != "deleted" {
+				reportCloud.Nodes++
+			}
+		}
+
+		for _, v := range c.vms {
+			s.advanceVM(v)
+
+			created, _ := time.Parse(time.RFC3339, v.CreatedAt)
+			released, _ := time.Parse(time.RFC3339, v.DeletedAt)
+			active := v.Status != "deleted"
+
+			aliveUntil := s.now()
+			if !active {
+				aliveUntil = released
+			}
+			if !created.Before(to) || aliveUntil.Before(from) {
+				// The VM was not alive during the period.
+				continue
+			}
+
+			var vmUsage one_api.FlexvmUsageReportUsage
+			if !created.Before(from) {
+				vmUsage.TotalVMsCreated = 1
+			}
+			if !active && released.Before(to) {
+				vmUsage.TotalVMsReleased = 1
+			}
+			if hoursFrom, hoursTo := later(created, from), earlier(aliveUntil, to); hoursFrom.Before(hoursTo) {
+				vmUsage.TotalVMHours = int(math.Ceil(hoursTo.Sub(hoursFrom).Hours()))
+			}
+
+			activeVMs := 0
+			if active {
+				activeVMs = 1
+			}
+
+			reportCloud.ActiveVMs += activeVMs
+			addUsage(&reportCloud.PeriodUsage, vmUsage)
+
+			for _, tag := range v.Tags {
+				i := slices.IndexFunc(report.ByTag, func(t one_api.FlexvmUsageReportTag) bool { return t.Tag == tag })
+				if i < 0 {
+					report.ByTag = append(report.ByTag, one_api.FlexvmUsageReportTag{Tag: tag})
+					i = len(report.ByTag) - 1
+				}
+				report.ByTag[i].ActiveVMs += activeVMs
+				addUsage(&report.ByTag[i].FlexvmUsageReportUsage, vmUsage)
+			}
+
+			reportVM := one_api.FlexvmUsageReportVM{
+				VMID:      v.ID,
+				Name:      v.Name,
+				Status:    v.Status,
+				Plan:      v.InstanceType.Name,
+				Image:     v.Image.Name,
+				CloudID:   c.ID,
+				Site:      c.Site,
+				Tags:      append([]string{}, v.Tags...),
+				CreatedAt: v.CreatedAt,
+				VMHours:   vmUsage.TotalVMHours,
+			}
+			if !active {
+				reportVM.ReleasedAt = v.DeletedAt
+			}
+			report.VMs = append(report.VMs, reportVM)
+		}
+
+		report.TotalNodes += reportCloud.Nodes
+		report.TotalActiveVMs += reportCloud.ActiveVMs
+		report.Clouds = append(report.Clouds, reportCloud)
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func addUsage(sum *one_api.FlexvmUsageReportUsage, u one_api.FlexvmUsageReportUsage) {
+	sum.TotalVMsCreated += u.TotalVMsCreated
+	sum.TotalVMsReleased += u.TotalVMsReleased
+	sum.TotalVMHours += u.TotalVMHours
+}
@@ -0,0 +1,82 @@
+package one_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Quota types of QuotaUsage.QuotaType.
+const (
+	QuotaTypeOnDemand = "onDemand"
+	QuotaTypeCommit   = "commit"
+)
+
+// LocationPartial identifies a location.
+type LocationPartial struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// InstanceTypePartial identifies an instance type.
+type InstanceTypePartial struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ServerQuotaUsage holds the quotas of a location.
+type ServerQuotaUsage struct {
+	Location   LocationPartial `json:"location"`
+	QuotaUsage []QuotaUsage    `json:"quotaUsage"`
+}
+
+// QuotaUsage is the number of servers of an instance type that can be
+// ordered, and the number that is ordered, on demand or under a contract.
+type QuotaUsage struct {
+	InstanceType InstanceTypePartial `json:"instanceType"`
+	QuotaType    string              `json:"quotaType"`
+	// ContractID is empty for the on demand quota.
+	ContractID string `json:"contractId"`
+	Quota      int    `json:"quota"`
+	Usage      int    `json:"usage"`
+}
+
+// CapacityCommit holds the committed capacity of a location.
+type CapacityCommit struct {
+	Location Location             `json:"location"`
+	Commits  []InstanceTypeCommit `json:"commits"`
+}
+
+// InstanceTypeCommit is the committed capacity of an instance type, and the
+// number of servers using it.
+type InstanceTypeCommit struct {
+	InstanceType InstanceType `json:"instanceType"`
+	Capacity     int          `json:"capacity"`
+	InUse        int          `json:"inUse"`
+}
+
+// ListQuotaUsage returns the quotas and usage of every location.
+func (c *Client) ListQuotaUsage(ctx context.Context) ([]ServerQuotaUsage, error) {
+	quotas, err := doList[ServerQuotaUsage](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: "flexMetal/quota/usage",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on calling list quota usage api: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// ListCapacityCommits returns the committed capacity of every location.
+func (c *Client) ListCapacityCommits(ctx context.Context) ([]CapacityCommit, error) {
+	commits, err := doList[CapacityCommit](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: "flexMetal/capacity/commit",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on calling list capacity commits api: %w", err)
+	}
+
+	return commits, nil
+}
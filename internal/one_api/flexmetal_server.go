@@ -2,9 +2,9 @@ package one_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -74,21 +74,9 @@ type Server struct {
 	ContractID  string   `json:"contractId"`
 }
 
-type ErrorResponse struct {
-	StatusCode   int    `json:"-"`
-	ErrorCode    int    `json:"errorCode"`
-	ErrorMessage string `json:"errorMessage"`
-	Errors       []struct {
-		Property string `json:"property"`
-		Message  string `json:"message"`
-	} `json:"errors"`
-}
-
-// ServerResponse can contain Server in case of a 200 response
-// or an ErrorResponse
-type ServerResponse struct {
-	ErrorResponse *ErrorResponse
-	Server        *Server
+// ServerRootPassword is the root password the API generated for a server.
+type ServerRootPassword struct {
+	RootPassword string `json:"rootPassword"`
 }
 
 type OperationStatus struct {
@@ -100,11 +88,6 @@ type OperationStatus struct {
 	UpdatedAt  string        `json:"updatedAt"`
 }
 
-type OperationStatusResponse struct {
-	ErrorResponse *ErrorResponse
-	Command       *Command
-}
-
 type Command struct {
 	UUID       string        `json:"uuid"`
 	ServerUUID string        `json:"server_uuid"`
@@ -128,149 +111,112 @@ type Paginator struct {
 	PerPage      int     `json:"per_page"`
 }
 
-func (c *Client) CreateServer(ctx context.Context, req CreateServerReq) (*ServerResponse, error) {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	resp, err := c.callAPI(ctx, http.MethodPost, "flexMetal", "servers", body, nil)
+func (c *Client) CreateServer(ctx context.Context, req CreateServerReq) (*Server, error) {
+	server, err := do[Server](ctx, c, apiRequest{
+		method:   http.MethodPost,
+		endpoint: flexMetalEndpoint,
+		path:     "servers",
+		body:     req,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error on calling create flexmetal server api: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var response ServerResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var serverResp []Server
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&serverResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
-
-	if len(serverResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
-
-	response.Server = &serverResp[0]
 
-	return &response, nil
+	return server, nil
 }
 
-func (c *Client) GetServer(ctx context.Context, id string) (*ServerResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, flexMetalEndpoint, fmt.Sprintf("servers/%s", id), nil, nil)
+func (c *Client) GetServer(ctx context.Context, id string) (*Server, error) {
+	server, err := do[Server](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s", id),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error on calling get flexmetal server api: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var response ServerResponse
+	return server, nil
+}
 
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
+// ListServers returns the servers with the given status and all of the given
+// tags. An empty status or no tags means no filter on it.
+func (c *Client) ListServers(ctx context.Context, status string, tags []string) ([]Server, error) {
+	req := apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexMetalEndpoint,
+		path:     "servers",
+		query:    url.Values{},
 	}
-
-	var serverResp []Server
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&serverResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if status != "" {
+		req.query.Set("status", status)
 	}
-
-	if len(serverResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
+	for _, tag := range tags {
+		req.query.Add("tag", tag)
 	}
 
-	response.Server = &serverResp[0]
-
-	return &response, nil
-}
-
-func (c *Client) DeleteServer(ctx context.Context, id string) (*ServerResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodDelete, flexMetalEndpoint, fmt.Sprintf("servers/%s", id), nil, nil)
+	servers, err := collect(paginate[Server](ctx, c, req))
 	if err != nil {
-		return nil, fmt.Errorf("error calling delete flexmetal server API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var response ServerResponse
-
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
+		return nil, fmt.Errorf("error on calling list flexmetal servers api: %w", err)
 	}
 
-	var serverResp []Server
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&serverResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
+	return servers, nil
+}
 
-	if len(serverResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
+// GetServerRootPassword returns the generated root password of the server. The
+// API only has one for Windows servers, during the first 24 hours after
+// installation, and returns a 404 otherwise.
+func (c *Client) GetServerRootPassword(ctx context.Context, id string) (*ServerRootPassword, error) {
+	password, err := do[ServerRootPassword](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s/rootPassword", id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on calling get flexmetal server root password api: %w", err)
 	}
 
-	response.Server = &serverResp[0]
-
-	return &response, nil
+	return password, nil
 }
 
-func (c *Client) ReinstallOs(ctx context.Context, serverID string, req PatchServerReq) (*ServerResponse, error) {
-	body, err := json.Marshal(req)
+func (c *Client) DeleteServer(ctx context.Context, id string) (*Server, error) {
+	server, err := do[Server](ctx, c, apiRequest{
+		method:   http.MethodDelete,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s", id),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
+		return nil, fmt.Errorf("error calling delete flexmetal server API: %w", err)
 	}
 
-	resp, err := c.callAPI(ctx, http.MethodPatch, flexMetalEndpoint, fmt.Sprintf("servers/%s", serverID), body, nil)
+	return server, nil
+}
+
+func (c *Client) ReinstallOs(ctx context.Context, serverID string, req PatchServerReq) (*Server, error) {
+	server, err := do[Server](ctx, c, apiRequest{
+		method:   http.MethodPatch,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s", serverID),
+		body:     req,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling reinstall flexmetal server API: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var response ServerResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var serverResp []Server
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&serverResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
-
-	if len(serverResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
-
-	response.Server = &serverResp[0]
 
-	return &response, nil
+	return server, nil
 }
 
-func (c *Client) GetOperationStatus(ctx context.Context, serverID string) (*OperationStatusResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, flexMetalEndpoint, fmt.Sprintf("servers/%s/commands", serverID), nil, map[string]string{"type": "update-server"})
+// GetOperationStatus returns the most recent update-server command of the
+// server, or an empty Command when the server has none.
+func (c *Client) GetOperationStatus(ctx context.Context, serverID string) (*Command, error) {
+	commands, err := doList[Command](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s/commands", serverID),
+		query:    url.Values{"type": {"update-server"}},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling get flexmetal server operation API: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var response OperationStatusResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var commands []Command
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(&commands)
-	if err != nil {
-		panic(err)
-	}
 
 	var firstStatus Command
 	if len(commands) > 0 {
@@ -278,77 +224,33 @@ func (c *Client) GetOperationStatus(ctx context.Context, serverID string) (*Oper
 		tflog.Debug(ctx, fmt.Sprintf("First operation status:\n%+v\n", firstStatus))
 	}
 
-	response.Command = &firstStatus
-	return &response, nil
+	return &firstStatus, nil
 }
 
-func (c *Client) AddTagToServer(ctx context.Context, serverID, tag string) (*ServerResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodPost, flexMetalEndpoint, fmt.Sprintf("servers/%s/tag/%s", serverID, tag), nil, nil)
+func (c *Client) AddTagToServer(ctx context.Context, serverID, tag string) (*Server, error) {
+	server, err := do[Server](ctx, c, apiRequest{
+		method:   http.MethodPost,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s/tag/%s", serverID, tag),
+		// Adding a tag that is already on the server is a no-op, so it is safe to retry.
+		retrySafe: true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error calling delete flexmetal server API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var response ServerResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var serverResp []Server
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&serverResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+		return nil, fmt.Errorf("error calling add tag to flexmetal server API: %w", err)
 	}
 
-	if len(serverResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
-
-	response.Server = &serverResp[0]
-
-	return &response, nil
+	return server, nil
 }
 
-func (c *Client) DeleteTagFromServer(ctx context.Context, serverID, tag string) (*ServerResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodDelete, flexMetalEndpoint, fmt.Sprintf("servers/%s/tag/%s", serverID, tag), nil, nil)
+func (c *Client) DeleteTagFromServer(ctx context.Context, serverID, tag string) (*Server, error) {
+	server, err := do[Server](ctx, c, apiRequest{
+		method:   http.MethodDelete,
+		endpoint: flexMetalEndpoint,
+		path:     fmt.Sprintf("servers/%s/tag/%s", serverID, tag),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error calling delete flexmetal server API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var response ServerResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var serverResp []Server
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&serverResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+		return nil, fmt.Errorf("error calling delete tag from flexmetal server API: %w", err)
 	}
 
-	if len(serverResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
-
-	response.Server = &serverResp[0]
-
-	return &response, nil
-}
-
-func decodeErrResponse(resp *http.Response) *ErrorResponse {
-	var errResponse ErrorResponse
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&errResponse); err != nil {
-		// if we cannot decode, it means response format is different, so we compute custom ErrorResponse with status code
-		errResponse = ErrorResponse{
-			ErrorMessage: fmt.Sprintf("Received %d status code.", resp.StatusCode),
-		}
-	}
-
-	errResponse.StatusCode = resp.StatusCode
-
-	return &errResponse
+	return server, nil
 }
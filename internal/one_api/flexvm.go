@@ -2,9 +2,9 @@ package one_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 const flexVMEndpoint = "flexVM"
@@ -21,6 +21,14 @@ const (
 	FlexvmErrCodeVMTerminal = 90002
 )
 
+// Commands accepted by FlexvmExecuteCommand.
+const (
+	FlexvmCommandStart  = "start"
+	FlexvmCommandStop   = "stop"
+	FlexvmCommandReboot = "reboot"
+	FlexvmCommandReset  = "reset"
+)
+
 type FlexvmCreateVMRequest struct {
 	Name             string          `json:"name"`
 	Description      string          `json:"description,omitempty"`
@@ -38,6 +46,10 @@ type FlexvmUserData struct {
 	IsBase64 bool   `json:"is_base64"`
 }
 
+type FlexvmVMCommandRequest struct {
+	Command string `json:"command"`
+}
+
 type FlexvmVM struct {
 	ID           string             `json:"id"`
 	Name         string             `json:"name"`
@@ -50,14 +62,16 @@ type FlexvmVM struct {
 	Node         *FlexvmNode        `json:"node"`
 	CreatedAt    string             `json:"createdAt"`
 	DeletedAt    string             `json:"deleted_at"`
+	Tags         []string           `json:"tags"`
 }
 
 type FlexvmInstanceType struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	VCPU   int    `json:"vcpu"`
-	Memory int    `json:"memory"`
-	Disk   int    `json:"disk"`
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	VCPU       int               `json:"vcpu"`
+	Memory     int               `json:"memory"`
+	Disk       int               `json:"disk"`
+	PCIDevices []FlexvmPCIDevice `json:"pciDevices,omitempty"`
 }
 
 type FlexvmImage struct {
@@ -87,72 +101,79 @@ type FlexvmNode struct {
 	Cloud        FlexvmCloud `json:"cloud"`
 }
 
-type FlexvmVMResponse struct {
-	ErrorResponse *ErrorResponse
-	VM            *FlexvmVM
-}
-
-func (c *Client) FlexvmCreateVM(ctx context.Context, cloudID string, req FlexvmCreateVMRequest) (*FlexvmVMResponse, error) {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	resp, err := c.callAPI(ctx, http.MethodPost, flexVMEndpoint, fmt.Sprintf("clouds/%s/vms", cloudID), body, nil)
+func (c *Client) FlexvmCreateVM(ctx context.Context, cloudID string, req FlexvmCreateVMRequest) (*FlexvmVM, error) {
+	vm, err := do[FlexvmVM](ctx, c, apiRequest{
+		method:   http.MethodPost,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s/vms", cloudID),
+		body:     req,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling flexvm create vm API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var response FlexvmVMResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var vm FlexvmVM
-	if err := json.NewDecoder(resp.Body).Decode(&vm); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
-
-	response.VM = &vm
-	return &response, nil
+	return vm, nil
 }
 
-func (c *Client) FlexvmGetVM(ctx context.Context, cloudID, vmID string) (*FlexvmVMResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, flexVMEndpoint, fmt.Sprintf("clouds/%s/vms/%s", cloudID, vmID), nil, nil)
+func (c *Client) FlexvmGetVM(ctx context.Context, cloudID, vmID string) (*FlexvmVM, error) {
+	vm, err := do[FlexvmVM](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s/vms/%s", cloudID, vmID),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling flexvm get vm API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var response FlexvmVMResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
+	return vm, nil
+}
+
+// FlexvmListVMs returns the VMs of a cloud. When status is not empty, only
+// the VMs with that status are returned.
+func (c *Client) FlexvmListVMs(ctx context.Context, cloudID, status string) ([]FlexvmVM, error) {
+	req := apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s/vms", cloudID),
+	}
+	if status != "" {
+		req.query = url.Values{"status": {status}}
 	}
 
-	var vm FlexvmVM
-	if err := json.NewDecoder(resp.Body).Decode(&vm); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	vms, err := collect(paginate[FlexvmVM](ctx, c, req))
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm list vms API: %w", err)
 	}
 
-	response.VM = &vm
-	return &response, nil
+	return vms, nil
 }
 
-func (c *Client) FlexvmDeleteVM(ctx context.Context, cloudID, vmID string) (*FlexvmVMResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodDelete, flexVMEndpoint, fmt.Sprintf("clouds/%s/vms/%s", cloudID, vmID), nil, nil)
+func (c *Client) FlexvmDeleteVM(ctx context.Context, cloudID, vmID string) error {
+	err := doNoContent(ctx, c, apiRequest{
+		method:   http.MethodDelete,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s/vms/%s", cloudID, vmID),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error calling delete flexvm API: %w", err)
+		return fmt.Errorf("error calling delete flexvm API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var response FlexvmVMResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
+	return nil
+}
+
+// FlexvmExecuteCommand sends a power command (one of the FlexvmCommand
+// constants) to the VM. The API accepts the command and executes it
+// asynchronously: poll the VM status to follow it.
+func (c *Client) FlexvmExecuteCommand(ctx context.Context, cloudID, vmID, command string) error {
+	err := doNoContent(ctx, c, apiRequest{
+		method:   http.MethodPost,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s/vms/%s/commands", cloudID, vmID),
+		body:     FlexvmVMCommandRequest{Command: command},
+	})
+	if err != nil {
+		return fmt.Errorf("error calling flexvm execute vm command API: %w", err)
 	}
 
-	return &response, nil
+	return nil
 }
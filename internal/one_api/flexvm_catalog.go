@@ -0,0 +1,43 @@
+package one_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// FlexvmPCIDevice is a PCI passthrough device required by an instance type.
+type FlexvmPCIDevice struct {
+	// Type is the kind of device: "gpu" or "nvme".
+	Type string `json:"type"`
+	// Spec is a human-readable specification of the device, e.g. "rtx4000".
+	Spec string `json:"spec"`
+}
+
+// FlexvmListImages returns the images VMs can be created from.
+func (c *Client) FlexvmListImages(ctx context.Context) ([]FlexvmImage, error) {
+	images, err := collect(paginate[FlexvmImage](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     "images",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm list images API: %w", err)
+	}
+
+	return images, nil
+}
+
+// FlexvmListInstanceTypes returns the instance types VMs can be based on.
+func (c *Client) FlexvmListInstanceTypes(ctx context.Context) ([]FlexvmInstanceType, error) {
+	instanceTypes, err := collect(paginate[FlexvmInstanceType](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     "instanceTypes",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm list instance types API: %w", err)
+	}
+
+	return instanceTypes, nil
+}
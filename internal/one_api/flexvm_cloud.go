@@ -2,7 +2,6 @@ package one_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -26,72 +25,55 @@ type FlexvmCloudObj struct {
 	CreatedAt    string `json:"created_at"`
 }
 
-type FlexvmCloudResponse struct {
-	ErrorResponse *ErrorResponse
-	Cloud         *FlexvmCloudObj
-}
-
-func (c *Client) FlexvmCreateCloud(ctx context.Context, req FlexvmCloudCreateRequest) (*FlexvmCloudResponse, error) {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	resp, err := c.callAPI(ctx, http.MethodPost, flexVMEndpoint, "clouds", body, nil)
+func (c *Client) FlexvmCreateCloud(ctx context.Context, req FlexvmCloudCreateRequest) (*FlexvmCloudObj, error) {
+	cloud, err := do[FlexvmCloudObj](ctx, c, apiRequest{
+		method:   http.MethodPost,
+		endpoint: flexVMEndpoint,
+		path:     "clouds",
+		body:     req,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling flexvm create cloud API: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var response FlexvmCloudResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
 
-	var cloud FlexvmCloudObj
-	if err := json.NewDecoder(resp.Body).Decode(&cloud); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
-
-	response.Cloud = &cloud
-	return &response, nil
+	return cloud, nil
 }
 
-func (c *Client) FlexvmGetCloud(ctx context.Context, cloudID string) (*FlexvmCloudResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, flexVMEndpoint, fmt.Sprintf("clouds/%s", cloudID), nil, nil)
+func (c *Client) FlexvmGetCloud(ctx context.Context, cloudID string) (*FlexvmCloudObj, error) {
+	cloud, err := do[FlexvmCloudObj](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s", cloudID),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling flexvm get cloud API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var response FlexvmCloudResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
+	return cloud, nil
+}
 
-	var cloud FlexvmCloudObj
-	if err := json.NewDecoder(resp.Body).Decode(&cloud); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+func (c *Client) FlexvmListClouds(ctx context.Context) ([]FlexvmCloudObj, error) {
+	clouds, err := collect(paginate[FlexvmCloudObj](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     "clouds",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm list clouds API: %w", err)
 	}
 
-	response.Cloud = &cloud
-	return &response, nil
+	return clouds, nil
 }
 
-func (c *Client) FlexvmDeleteCloud(ctx context.Context, cloudID string) (*FlexvmCloudResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodDelete, flexVMEndpoint, fmt.Sprintf("clouds/%s", cloudID), nil, nil)
+func (c *Client) FlexvmDeleteCloud(ctx context.Context, cloudID string) error {
+	err := doNoContent(ctx, c, apiRequest{
+		method:   http.MethodDelete,
+		endpoint: flexVMEndpoint,
+		path:     fmt.Sprintf("clouds/%s", cloudID),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error calling flexvm delete cloud API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var response FlexvmCloudResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
+		return fmt.Errorf("error calling flexvm delete cloud API: %w", err)
 	}
 
-	return &response, nil
+	return nil
 }
@@ -0,0 +1,168 @@
+package one_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// FlexvmCapacityResource is the utilization of a resource. Total is always
+// InUse plus Available.
+type FlexvmCapacityResource struct {
+	Total     int `json:"total"`
+	InUse     int `json:"in_use"`
+	Available int `json:"available"`
+}
+
+// FlexvmCapacityPCIDevice is the utilization of a kind of PCI passthrough
+// device, e.g. "gpu".
+type FlexvmCapacityPCIDevice struct {
+	Type string `json:"type"`
+	FlexvmCapacityResource
+}
+
+type FlexvmCapacityResources struct {
+	// VCPU counts vCPU cores.
+	VCPU FlexvmCapacityResource `json:"vcpu"`
+	// Memory counts megabytes.
+	Memory     FlexvmCapacityResource    `json:"memory"`
+	PCIDevices []FlexvmCapacityPCIDevice `json:"pci_devices"`
+}
+
+// FlexvmCapacityNodeGroup counts the nodes of a bare-metal instance type.
+type FlexvmCapacityNodeGroup struct {
+	InstanceType string `json:"instance_type"`
+	Quantity     int    `json:"quantity"`
+}
+
+// FlexvmCapacity is a capacity snapshot of the nodes in scope: a single Cloud,
+// or the whole organization.
+type FlexvmCapacity struct {
+	Nodes      int                       `json:"nodes"`
+	NodeGroups []FlexvmCapacityNodeGroup `json:"node_groups"`
+	Resources  FlexvmCapacityResources   `json:"resources"`
+}
+
+// FlexvmCloudCapacity is the capacity snapshot of a Cloud.
+type FlexvmCloudCapacity struct {
+	FlexvmCloudObj
+	FlexvmCapacity
+}
+
+// PCIDevice returns the utilization of the PCI passthrough devices of type
+// deviceType. It is zero when there are no such devices in scope.
+func (r FlexvmCapacityResources) PCIDevice(deviceType string) FlexvmCapacityResource {
+	for _, d := range r.PCIDevices {
+		if d.Type == deviceType {
+			return d.FlexvmCapacityResource
+		}
+	}
+	return FlexvmCapacityResource{}
+}
+
+// FlexvmListCloudCapacities returns the capacity of each Cloud of the
+// organization.
+func (c *Client) FlexvmListCloudCapacities(ctx context.Context) ([]FlexvmCloudCapacity, error) {
+	capacities, err := collect(paginate[FlexvmCloudCapacity](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     "capacity",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm list capacity API: %w", err)
+	}
+
+	return capacities, nil
+}
+
+// FlexvmGetCapacity returns the organization-wide capacity.
+func (c *Client) FlexvmGetCapacity(ctx context.Context) (*FlexvmCapacity, error) {
+	capacity, err := do[FlexvmCapacity](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     "reports/capacity",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm capacity report API: %w", err)
+	}
+
+	return capacity, nil
+}
+
+// FlexvmUsageReportPeriod is the half-open reporting period [Start, End), in
+// ISO 8601.
+type FlexvmUsageReportPeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// FlexvmUsageReportUsage aggregates VM activity over the reporting period.
+type FlexvmUsageReportUsage struct {
+	TotalVMsCreated  int `json:"total_vms_created"`
+	TotalVMsReleased int `json:"total_vms_released"`
+	TotalVMHours     int `json:"total_vm_hours"`
+}
+
+type FlexvmUsageReportCloud struct {
+	ID           string                 `json:"id"`
+	Site         string                 `json:"site"`
+	InstanceType string                 `json:"instance_type"`
+	Nodes        int                    `json:"nodes"`
+	ActiveVMs    int                    `json:"active_vms"`
+	PeriodUsage  FlexvmUsageReportUsage `json:"period_usage"`
+}
+
+// FlexvmUsageReportTag aggregates the usage of the VMs carrying a tag.
+type FlexvmUsageReportTag struct {
+	Tag       string `json:"tag"`
+	ActiveVMs int    `json:"active_vms"`
+	FlexvmUsageReportUsage
+}
+
+type FlexvmUsageReportVM struct {
+	VMID string `json:"vm_id"`
+	Name string `json:"name"`
+	// Status is the status of the VM when the report was generated.
+	Status string `json:"status"`
+	// Plan is the name of the instance type of the VM.
+	Plan      string   `json:"plan"`
+	Image     string   `json:"image"`
+	CloudID   string   `json:"cloud_id"`
+	Site      string   `json:"site"`
+	Tags      []string `json:"tags"`
+	CreatedAt string   `json:"created_at"`
+	// ReleasedAt is empty while the VM is alive.
+	ReleasedAt string `json:"released_at,omitempty"`
+	VMHours    int    `json:"vm_hours"`
+}
+
+type FlexvmUsageReport struct {
+	Period         FlexvmUsageReportPeriod  `json:"period"`
+	TotalNodes     int                      `json:"total_nodes"`
+	TotalActiveVMs int                      `json:"total_active_vms"`
+	Clouds         []FlexvmUsageReportCloud `json:"clouds"`
+	ByTag          []FlexvmUsageReportTag   `json:"by_tag"`
+	VMs            []FlexvmUsageReportVM    `json:"vms"`
+}
+
+// FlexvmGetUsageReport returns the usage report of the period [start, end),
+// both in ISO 8601. When both are empty, the period is the current calendar
+// month.
+func (c *Client) FlexvmGetUsageReport(ctx context.Context, start, end string) (*FlexvmUsageReport, error) {
+	req := apiRequest{
+		method:   http.MethodGet,
+		endpoint: flexVMEndpoint,
+		path:     "reports/monthly",
+	}
+	if start != "" || end != "" {
+		req.query = url.Values{"start": {start}, "end": {end}}
+	}
+
+	report, err := do[FlexvmUsageReport](ctx, c, req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling flexvm monthly usage report API: %w", err)
+	}
+
+	return report, nil
+}
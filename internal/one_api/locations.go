@@ -2,7 +2,6 @@ package one_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -20,21 +19,41 @@ type Location struct {
 const locationsEndpoint = "flexMetal/location"
 
 func (c *Client) ListLocations(ctx context.Context) ([]Location, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, locationsEndpoint, "", nil, nil)
+	locations, err := doList[Location](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: locationsEndpoint,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error on calling list locations api: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var locationsResp []Location
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&locationsResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
+	return locations, nil
+}
 
-	if len(locationsResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
+// InstanceType is a FlexMetal instance type available in a location.
+type InstanceType struct {
+	ID             int    `json:"id"`
+	LocationID     int    `json:"locationId"`
+	Name           string `json:"name"`
+	Sockets        int    `json:"sockets"`
+	Cores          int    `json:"cores"`
+	Memory         int    `json:"memory"`
+	MemoryType     string `json:"memoryType"`
+	Storage        int    `json:"storage"`
+	StorageType    string `json:"storageType"`
+	GenerationName string `json:"generationName"`
+}
+
+// ListInstanceTypes returns the FlexMetal instance types of a location.
+func (c *Client) ListInstanceTypes(ctx context.Context, locationID int) ([]InstanceType, error) {
+	instanceTypes, err := doList[InstanceType](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: locationsEndpoint,
+		path:     fmt.Sprintf("%d/instanceTypes", locationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on calling list instance types api: %w", err)
 	}
 
-	return locationsResp, nil
+	return instanceTypes, nil
 }
@@ -1,43 +1,173 @@
 package one_api
 
 import (
-	"context"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"net/http/httputil"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+// maxLoggedBodySize is the number of bytes of a request or response body that
+// is logged; the rest is cut off.
+const maxLoggedBodySize = 4 << 10
+
+// redactedMask replaces secret values in the logs. It is the same mask tflog
+// uses for masked fields.
+const redactedMask = "***"
+
+// redactedHeaders hold credentials and are masked in the logs. They are in
+// canonical form, as header log fields are keyed by the canonical name.
+var redactedHeaders = []string{
+	"Private-Token",
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// redactedJSONFields are the (case-insensitive) JSON fields of request and
+// response bodies that hold secrets, e.g. post-install scripts and cloud-init
+// user-data, which commonly contain passwords or tokens.
+var redactedJSONFields = map[string]bool{
+	"postinstallscript": true,
+	"user_data":         true,
+	"rootpassword":      true,
+	"password":          true,
+}
+
 // loggingRoundTripper logs One API request and response if TF_LOG=DEBUG
 // see: https://developer.hashicorp.com/terraform/plugin/log/writing
+//
+// Logs are safe to share: credential headers are masked, secret JSON fields
+// are redacted and bodies are truncated to maxLoggedBodySize.
 type loggingRoundTripper struct {
 	next http.RoundTripper
-	ctx  context.Context
 }
 
 func (l *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := tflog.MaskFieldValuesWithFieldKeys(req.Context(), redactedHeaders...)
+	if token := req.Header.Get("PRIVATE-TOKEN"); token != "" {
+		// The API key is masked wherever it shows up, e.g. echoed in a body.
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, token)
+	}
 	start := time.Now()
 
-	reqBody, err := httputil.DumpRequest(req, true)
+	reqBody, err := readRequestBody(req)
 	if err != nil {
-		return nil, fmt.Errorf("error dumping request: %w", err)
+		return nil, fmt.Errorf("error reading request body: %w", err)
 	}
 
-	tflog.Debug(l.ctx, "Sending api request", map[string]interface{}{"body": string(reqBody), "method": req.Method})
+	fields := headerFields(req.Header)
+	fields["method"] = req.Method
+	fields["url"] = req.URL.String()
+	fields["body"] = loggableBody(reqBody)
+	tflog.Debug(ctx, "Sending api request", fields)
 
 	resp, err := l.next.RoundTrip(req)
 	if err != nil {
 		return resp, err
 	}
 
-	respBody, err := httputil.DumpResponse(resp, true)
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
 	if err != nil {
-		return nil, fmt.Errorf("error dumping response: %w", err)
+		return nil, fmt.Errorf("error reading response body: %w", err)
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
 
-	tflog.Debug(l.ctx, "Getting api response", map[string]interface{}{"body": string(respBody), "duration": time.Since(start)})
+	fields = headerFields(resp.Header)
+	fields["status_code"] = resp.StatusCode
+	fields["duration"] = time.Since(start).String()
+	fields["body"] = loggableBody(respBody)
+	tflog.Debug(ctx, "Getting api response", fields)
 
 	return resp, nil
 }
+
+// readRequestBody returns the body of req, leaving req.Body readable.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// headerFields returns a log field per header, keyed by the header name, so
+// that tflog can mask the redactedHeaders.
+func headerFields(h http.Header) map[string]interface{} {
+	fields := make(map[string]interface{}, len(h)+4)
+	for name, values := range h {
+		fields[name] = strings.Join(values, ", ")
+	}
+
+	return fields
+}
+
+// loggableBody returns body with its secret JSON fields redacted, truncated to
+// maxLoggedBodySize.
+func loggableBody(body []byte) string {
+	body = redactBody(body)
+
+	if len(body) > maxLoggedBodySize {
+		// A multi-byte character cut in half is dropped.
+		return fmt.Sprintf("%s... (%d more bytes)", strings.ToValidUTF8(string(body[:maxLoggedBodySize]), ""), len(body)-maxLoggedBodySize)
+	}
+
+	return string(body)
+}
+
+// redactBody returns body with its secret JSON fields redacted. A body that is
+// not JSON is returned as is.
+func redactBody(body []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+
+	var redacted bytes.Buffer
+	enc := json.NewEncoder(&redacted)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(redactJSON(v)); err != nil {
+		return body
+	}
+
+	return bytes.TrimSuffix(redacted.Bytes(), []byte("\n"))
+}
+
+// redactJSON replaces the value of every redactedJSONFields field in the
+// decoded JSON v, at any depth, with redactedMask.
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if redactedJSONFields[strings.ToLower(k)] {
+				if field != nil && field != "" {
+					v[k] = redactedMask
+				}
+				continue
+			}
+			v[k] = redactJSON(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+
+	return v
+}
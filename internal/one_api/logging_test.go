@@ -0,0 +1,93 @@
+package one_api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingRoundTripperRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	const apiKey = "secret-api-key"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret-session")
+		_, _ = w.Write([]byte(`[{"uuid": "abc", "postInstallScript": "echo secret-script", "note": "key ` + apiKey + `"}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(t.Context(), &output)
+
+	c, err := NewClient(apiKey, srv.URL)
+	require.NoError(t, err)
+
+	_, err = c.CreateServer(ctx, CreateServerReq{
+		Name:              "web-1",
+		PostInstallScript: "#!/bin/sh\necho secret-script",
+	})
+	require.NoError(t, err)
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, "***", entries[0]["Private-Token"])
+	require.Contains(t, entries[0]["body"], `"name":"web-1"`)
+	require.Equal(t, "***", entries[1]["Set-Cookie"])
+	require.Contains(t, entries[1]["body"], `"uuid":"abc"`)
+
+	for _, secret := range []string{apiKey, "secret-script", "secret-session"} {
+		require.NotContains(t, output.String(), secret)
+	}
+}
+
+func TestLoggableBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "nested secret fields are redacted",
+			body: `{"name": "vm", "user_data": {"data": "password: hunter2", "encoding": "plain"}, "items": [{"rootPassword": "hunter2"}]}`,
+			want: `{"items":[{"rootPassword":"***"}],"name":"vm","user_data":"***"}`,
+		},
+		{
+			name: "empty secret fields are kept",
+			body: `{"postInstallScript": "", "id": 12345678901234567890}`,
+			want: `{"id":12345678901234567890,"postInstallScript":""}`,
+		},
+		{
+			name: "non-JSON body is logged as is",
+			body: `<html>Bad gateway</html>`,
+			want: `<html>Bad gateway</html>`,
+		},
+		{
+			name: "empty body",
+			body: ``,
+			want: ``,
+		},
+		{
+			name: "large body is truncated",
+			body: strings.Repeat("a", maxLoggedBodySize+10),
+			want: strings.Repeat("a", maxLoggedBodySize) + "... (10 more bytes)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, loggableBody([]byte(tt.body)))
+		})
+	}
+}
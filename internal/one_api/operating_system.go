@@ -0,0 +1,47 @@
+package one_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const operatingSystemEndpoint = "operatingsystem"
+
+// Operating system groups of OperatingSystem.OsGroup.
+const (
+	OsGroupWindows = 1
+	OsGroupLinux   = 2
+)
+
+// OperatingSystem is an operating system that can be installed on servers.
+// The API returns its flags as 0 or 1.
+type OperatingSystem struct {
+	ID                    int    `json:"id"`
+	Name                  string `json:"name"`
+	Slug                  string `json:"slug"`
+	OsGroup               int    `json:"osGroup"`
+	IsOdp                 int    `json:"isOdp"`
+	AvailableForFlexMetal int    `json:"availableForFlexMetal"`
+	Active                int    `json:"active"`
+}
+
+// UsableForFlexMetal reports whether FlexMetal servers can be created with
+// the operating system.
+func (o OperatingSystem) UsableForFlexMetal() bool {
+	return o.Active == 1 && o.AvailableForFlexMetal == 1
+}
+
+// ListOperatingSystems returns all operating systems, including the ones not
+// available for FlexMetal.
+func (c *Client) ListOperatingSystems(ctx context.Context) ([]OperatingSystem, error) {
+	operatingSystems, err := doList[OperatingSystem](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: operatingSystemEndpoint,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on calling list operating systems api: %w", err)
+	}
+
+	return operatingSystems, nil
+}
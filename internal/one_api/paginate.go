@@ -0,0 +1,138 @@
+package one_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// rangedDataHeader selects a page of a list endpoint on the request, e.g.
+	// "start=0,results=25", and describes the returned page on the response,
+	// e.g. "start=0,results=25,total=120".
+	rangedDataHeader = "RANGED-DATA"
+
+	// pageSize is the number of results requested per page.
+	pageSize = 100
+
+	// maxPages caps the number of pages fetched by paginate, as a safety net
+	// against an endpoint that keeps returning full pages.
+	maxPages = 1000
+)
+
+// ErrTooManyPages is returned by list calls that reached maxPages before the
+// API reported the last page. The results are not returned in that case, as
+// they would be incomplete.
+var ErrTooManyPages = errors.New("too many pages")
+
+// paginate returns an iterator over every item of the list endpoint described
+// by req, fetching one page at a time through the RANGED-DATA header. The next
+// page is only requested once the items of the current page are consumed.
+//
+// The iteration stops after the last page, which is recognised by the total
+// in the RANGED-DATA response header or, when the API does not send it, by a
+// page that is not full. A page starting with the first item of the list is
+// not yielded and ends the iteration too: the endpoint ignores ranges and
+// returned the whole list again. On failure, a single (zero, err) pair is
+// yielded.
+//
+// Only use paginate for endpoints documented with RANGED-DATA, and doList for
+// the others.
+func paginate[T any](ctx context.Context, c *Client, req apiRequest) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero, first T
+
+		for start, page := 0, 0; ; page++ {
+			if page == maxPages {
+				yield(zero, fmt.Errorf("%w: stopped after %d pages of %d results", ErrTooManyPages, maxPages, pageSize))
+				return
+			}
+
+			pageReq := req
+			pageReq.headers = maps.Clone(req.headers)
+			if pageReq.headers == nil {
+				pageReq.headers = map[string]string{}
+			}
+			pageReq.headers[rangedDataHeader] = fmt.Sprintf("start=%d,results=%d", start, pageSize)
+
+			items, header, err := doListWithHeader[T](ctx, c, pageReq)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			if len(items) > 0 {
+				if page == 0 {
+					first = items[0]
+				} else if reflect.DeepEqual(items[0], first) {
+					return
+				}
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if isLastPage(header.Get(rangedDataHeader), start, len(items)) {
+				return
+			}
+			start += len(items)
+		}
+	}
+}
+
+// isLastPage reports whether the page of n items requested at start is the
+// last one. An endpoint that does not support ranges returns all items at
+// once, which shows as a page larger than requested.
+func isLastPage(rangedData string, start, n int) bool {
+	if n != pageSize {
+		return true
+	}
+
+	if total, ok := rangedDataTotal(rangedData); ok {
+		return start+n >= total
+	}
+
+	return false
+}
+
+// rangedDataTotal returns the total number of items from a RANGED-DATA
+// response header value such as "start=0,results=25,total=120".
+func rangedDataTotal(value string) (int, bool) {
+	for _, field := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found || !strings.EqualFold(key, "total") {
+			continue
+		}
+
+		total, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || total < 0 {
+			return 0, false
+		}
+
+		return total, true
+	}
+
+	return 0, false
+}
+
+// collect gathers every item yielded by seq into a slice, or returns the first
+// error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	list := []T{}
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+
+	return list, nil
+}
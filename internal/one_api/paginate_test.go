@@ -0,0 +1,176 @@
+package one_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newRangedServer serves total tags, honouring the RANGED-DATA request header
+// unless ignoreRange is set. With sendTotal, the response header reports the
+// total number of tags.
+func newRangedServer(t *testing.T, total int, ignoreRange, sendTotal bool, requests *atomic.Int32) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		start, results := 0, total
+		if !ignoreRange {
+			_, err := fmt.Sscanf(r.Header.Get(rangedDataHeader), "start=%d,results=%d", &start, &results)
+			require.NoError(t, err)
+		}
+
+		end := min(start+results, total)
+		if sendTotal {
+			w.Header().Set(rangedDataHeader, fmt.Sprintf("start=%d,results=%d,total=%d", start, end-start, total))
+		}
+
+		_, _ = w.Write([]byte("["))
+		for i := start; i < end; i++ {
+			if i > start {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = fmt.Fprintf(w, `{"tag": "tag-%d"}`, i)
+		}
+		_, _ = w.Write([]byte("]"))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient("key", srv.URL)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		total        int
+		ignoreRange  bool
+		sendTotal    bool
+		wantRequests int32
+	}{
+		{
+			name:         "last page is detected by total",
+			total:        2 * pageSize,
+			sendTotal:    true,
+			wantRequests: 2,
+		},
+		{
+			name:         "last page is detected by a short page",
+			total:        2*pageSize + 1,
+			wantRequests: 3,
+		},
+		{
+			name:         "empty list",
+			total:        0,
+			wantRequests: 1,
+		},
+		{
+			name:         "endpoint without ranges returns everything at once",
+			total:        pageSize + 50,
+			ignoreRange:  true,
+			wantRequests: 1,
+		},
+		{
+			name:         "endpoint without ranges returns a full page at once",
+			total:        pageSize,
+			ignoreRange:  true,
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			c := newRangedServer(t, tt.total, tt.ignoreRange, tt.sendTotal, &requests)
+
+			tags, err := c.ListTags(context.Background(), "")
+			require.NoError(t, err)
+			require.Len(t, tags, tt.total)
+			for i, tag := range tags {
+				require.Equal(t, fmt.Sprintf("tag-%d", i), tag.Tag)
+			}
+			require.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+}
+
+func TestPaginateStopsEarly(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	c := newRangedServer(t, 3*pageSize, false, true, &requests)
+
+	n := 0
+	for _, err := range paginate[Tag](context.Background(), c, apiRequest{method: http.MethodGet, endpoint: tagEndpoint}) {
+		require.NoError(t, err)
+		if n++; n == pageSize+1 {
+			break
+		}
+	}
+
+	require.Equal(t, int32(2), requests.Load())
+}
+
+func TestPaginatePageLimit(t *testing.T) {
+	t.Parallel()
+
+	// Every page is full and new, and no total is sent, so the end is never
+	// reached.
+	var page atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := page.Add(1)
+		_, _ = w.Write([]byte("["))
+		for i := range pageSize {
+			if i > 0 {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = fmt.Fprintf(w, `{"tag": "tag-%d-%d"}`, p, i)
+		}
+		_, _ = w.Write([]byte("]"))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient("key", srv.URL)
+	require.NoError(t, err)
+
+	_, err = c.ListTags(context.Background(), "")
+	require.ErrorIs(t, err, ErrTooManyPages)
+}
+
+func TestRangedDataTotal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value     string
+		wantTotal int
+		wantOK    bool
+	}{
+		{value: "start=0,results=25,total=120", wantTotal: 120, wantOK: true},
+		{value: "start=0, results=25, TOTAL=7", wantTotal: 7, wantOK: true},
+		{value: "start=0,results=25", wantOK: false},
+		{value: "total=many", wantOK: false},
+		{value: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			total, ok := rangedDataTotal(tt.value)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantTotal, total)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package one_api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps the number of requests per second the Client sends. The
+// limit is shared by every caller of the Client, so all resources and data
+// sources of a provider instance (and their status polling loops) draw from
+// the same budget. Zero, the default, disables the limit.
+func WithRateLimit(requestsPerSecond float64) Option {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(requestsPerSecond)
+	}
+}
+
+// rateLimiter is a token bucket that refills at rate tokens per second, up to
+// burst tokens. Every request attempt takes one token and waits when the
+// bucket is empty.
+//
+// Independently of the rate, the API can ask the Client to back off with a 429
+// response; pause then holds back every caller, not only the one that got
+// throttled, since they all share the same API key.
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// newRateLimiter returns a limiter allowing requestsPerSecond requests per
+// second, with a burst of one second worth of requests. A non-positive
+// requestsPerSecond disables the rate limit; pause still applies.
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	burst := math.Max(1, math.Ceil(requestsPerSecond))
+	return &rateLimiter{
+		rate:   requestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a request may be sent, or until ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		l.cancel()
+		return err
+	}
+
+	return nil
+}
+
+// reserve takes a token and returns how long the caller has to wait before
+// using it. The bucket may go negative: callers queue up behind each other.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delay := max(l.pausedUntil.Sub(now), 0)
+
+	if l.rate > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		l.tokens--
+		if l.tokens < 0 {
+			delay = max(delay, time.Duration(-l.tokens/l.rate*float64(time.Second)))
+		}
+	}
+
+	return delay
+}
+
+// cancel returns the token taken by a reservation that was not used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+1)
+	}
+}
+
+// pause holds back every request for d.
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
@@ -0,0 +1,35 @@
+package one_api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	t.Parallel()
+
+	l := newRateLimiter(2)
+	now := l.last
+
+	// The burst is available right away, after which callers queue up.
+	require.Zero(t, l.reserve(now))
+	require.Zero(t, l.reserve(now))
+	require.Equal(t, 500*time.Millisecond, l.reserve(now))
+	require.Equal(t, time.Second, l.reserve(now))
+
+	// Tokens are refilled over time.
+	require.Equal(t, 500*time.Millisecond, l.reserve(now.Add(time.Second)))
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	t.Parallel()
+
+	// Without a rate, only a pause holds back requests.
+	l := newRateLimiter(0)
+	require.Zero(t, l.reserve(time.Now()))
+
+	l.pause(time.Minute)
+	require.InDelta(t, time.Minute, l.reserve(time.Now()), float64(time.Second))
+}
@@ -0,0 +1,150 @@
+package one_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// errEmptyResponse is returned when the API responds successfully but without
+// the object the endpoint is expected to return.
+var errEmptyResponse = errors.New("unexpected empty response")
+
+// apiRequest describes a One API call made through do, doList, doNoContent or
+// paginate.
+type apiRequest struct {
+	method   string
+	endpoint string
+	path     string
+	// body, when not nil, is marshalled to JSON and sent as the request body.
+	body    any
+	query   url.Values
+	headers map[string]string
+	// retrySafe allows a non-idempotent request to be retried. Only set it for
+	// endpoints where repeating the request has no additional effect, e.g.
+	// adding a tag to a server.
+	retrySafe bool
+}
+
+// do sends req and decodes the response body into a T. Some endpoints return
+// a single object wrapped in an array; both forms are accepted and the first
+// element of an array is returned. An empty body, or an empty array, is
+// reported as errEmptyResponse.
+//
+// A response with a status >= 400 is returned as an *APIError.
+func do[T any](ctx context.Context, c *Client, req apiRequest) (*T, error) {
+	body, _, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errEmptyResponse
+	}
+
+	if body[0] != '[' {
+		var obj T
+		if err := json.Unmarshal(body, &obj); err != nil {
+			return nil, fmt.Errorf("error decoding response: %w", err)
+		}
+		return &obj, nil
+	}
+
+	var list []T
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, errEmptyResponse
+	}
+
+	return &list[0], nil
+}
+
+// doList sends req and decodes the JSON array in the response body into a
+// []T. An empty body is treated as an empty list. Error handling is the same
+// as for do.
+func doList[T any](ctx context.Context, c *Client, req apiRequest) ([]T, error) {
+	list, _, err := doListWithHeader[T](ctx, c, req)
+	return list, err
+}
+
+// doListWithHeader is doList, but also returns the response headers.
+func doListWithHeader[T any](ctx context.Context, c *Client, req apiRequest) ([]T, http.Header, error) {
+	body, header, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := []T{}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return list, header, nil
+	}
+
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return list, header, nil
+}
+
+// doNoContent sends req and discards the response body, for endpoints that
+// return no content (e.g. 202 Accepted or 204 No Content). Error handling is
+// the same as for do.
+func doNoContent(ctx context.Context, c *Client, req apiRequest) error {
+	_, _, err := c.roundTrip(ctx, req)
+	return err
+}
+
+// roundTrip sends req and returns the raw response body and headers, or an
+// *APIError when the API responds with a status >= 400.
+func (c *Client) roundTrip(ctx context.Context, req apiRequest) ([]byte, http.Header, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling request: %w", err)
+		}
+	}
+
+	retryable := req.retrySafe || isIdempotent(req.method)
+
+	resp, err := c.send(ctx, req.method, c.apiURL(req.endpoint, req.path, req.query), body, req.headers, retryable)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, nil, decodeAPIError(resp)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	return respBody, resp.Header, nil
+}
+
+func decodeAPIError(resp *http.Response) *APIError {
+	var apiErr APIError
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&apiErr); err != nil {
+		// if we cannot decode, it means response format is different, so we compute custom APIError with status code
+		apiErr = APIError{
+			ErrorMessage: fmt.Sprintf("Received %d status code.", resp.StatusCode),
+		}
+	}
+
+	apiErr.StatusCode = resp.StatusCode
+
+	return &apiErr
+}
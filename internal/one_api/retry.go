@@ -0,0 +1,175 @@
+package one_api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// RetryConfig controls how the Client retries requests that failed with a
+// transient error: a transport-level error (e.g. a connection reset) or one of
+// the retryableStatusCodes. Only idempotent requests are retried, plus the
+// POST requests the API documents as safe to repeat (see apiRequest.retrySafe).
+type RetryConfig struct {
+	// MaxRetries is the number of retries made after the first attempt.
+	// Zero disables retries.
+	MaxRetries int
+	// MinWait is the backoff before the first retry. It doubles on every
+	// subsequent retry, up to MaxWait.
+	MinWait time.Duration
+	// MaxWait caps the exponential backoff between two attempts. A longer
+	// Retry-After sent by the API is still honoured, up to MaxRetryAfter.
+	MaxWait time.Duration
+}
+
+// MaxRetryAfter is the longest Retry-After the Client honours when MaxWait is
+// shorter. A response asking to wait longer is returned instead of retried,
+// as waiting would hold back every caller of the Client for that long.
+const MaxRetryAfter = 2 * time.Minute
+
+// DefaultRetryConfig is used by NewClient unless WithRetry is passed.
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries: 4,
+	MinWait:    1 * time.Second,
+	MaxWait:    30 * time.Second,
+}
+
+// WithRetry sets the retry behaviour of the Client.
+func WithRetry(cfg RetryConfig) Option {
+	return func(c *Client) {
+		c.retry = cfg
+	}
+}
+
+// retryableStatusCodes are the response status codes considered transient.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// isIdempotent reports whether a request with the given method can be safely
+// repeated.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether the outcome of an attempt is a transient failure.
+// Errors caused by the request context being cancelled or timing out are final.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) &&
+			!errors.Is(err, ErrNoRecordedInteraction)
+	}
+	return retryableStatusCodes[resp.StatusCode]
+}
+
+// retryAfterLimit returns the longest Retry-After that is honoured.
+func (r RetryConfig) retryAfterLimit() time.Duration {
+	return max(r.MaxWait, MaxRetryAfter)
+}
+
+// backoff returns how long to wait before the retry following the given
+// (zero-based) attempt. A Retry-After header on resp takes precedence;
+// otherwise an exponential backoff with full jitter is used. It reports false
+// when the Retry-After exceeds retryAfterLimit, and no retry should be made.
+func (r RetryConfig) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if wait, ok := retryAfter(resp); ok {
+		return wait, wait <= r.retryAfterLimit()
+	}
+
+	wait := r.MinWait
+	for i := 0; i < attempt && wait < r.MaxWait; i++ {
+		wait *= 2
+	}
+	wait = min(wait, r.MaxWait)
+	if wait <= 0 {
+		return 0, true
+	}
+
+	// Full jitter spreads out the retries of concurrent callers.
+	return time.Duration(rand.Int64N(int64(wait))) + 1, true
+}
+
+// retryAfter parses the Retry-After header of resp, which holds either a
+// number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// deadlineAllows reports whether ctx is still alive after waiting for wait.
+func deadlineAllows(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > wait
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drainAndClose discards the body of a response that is about to be retried,
+// so that its connection can be reused.
+func drainAndClose(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+func logRetry(ctx context.Context, method, apiURL string, retry int, wait time.Duration, resp *http.Response, err error) {
+	fields := map[string]interface{}{
+		"method": method,
+		"url":    apiURL,
+		"retry":  retry,
+		"wait":   wait.String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	if resp != nil {
+		fields["status_code"] = resp.StatusCode
+	}
+
+	tflog.Warn(ctx, "Retrying api request after a transient failure", fields)
+}
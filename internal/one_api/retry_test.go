@@ -0,0 +1,156 @@
+package one_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRetries(t *testing.T) {
+	t.Parallel()
+
+	retry := RetryConfig{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: 5 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		method       string
+		statuses     []int
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{
+			name:         "GET is retried until it succeeds",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "GET gives up after MaxRetries",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 3,
+		},
+		{
+			name:         "non-transient status is not retried",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusNotFound},
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+		{
+			name:         "POST is not retried",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusBadGateway, http.StatusOK},
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1)) - 1
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses)-1)])
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient("key", srv.URL, WithRetry(retry))
+			require.NoError(t, err)
+
+			resp, err := c.send(context.Background(), tt.method, c.apiURL(flexMetalEndpoint, "servers", nil), nil, nil, isIdempotent(tt.method))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+			require.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestClientRetrySafePost(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient("key", srv.URL, WithRetry(RetryConfig{MaxRetries: 1, MinWait: time.Millisecond, MaxWait: time.Millisecond}))
+	require.NoError(t, err)
+
+	err = doNoContent(context.Background(), c, apiRequest{
+		method:    http.MethodPost,
+		endpoint:  flexMetalEndpoint,
+		path:      "servers/abc/tag/env",
+		retrySafe: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempts.Load())
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	t.Parallel()
+
+	r := RetryConfig{MaxRetries: 10, MinWait: time.Second, MaxWait: 8 * time.Second}
+
+	for attempt := range 10 {
+		wait, ok := r.backoff(attempt, nil)
+		require.True(t, ok)
+		require.Greater(t, wait, time.Duration(0))
+		require.LessOrEqual(t, wait, min(time.Second<<attempt, r.MaxWait))
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"42"}}}
+	wait, ok := r.backoff(0, resp)
+	require.True(t, ok)
+	require.Equal(t, 42*time.Second, wait)
+
+	resp = &http.Response{Header: http.Header{"Retry-After": []string{"86400"}}}
+	_, ok = r.backoff(0, resp)
+	require.False(t, ok)
+}
+
+func TestClientIgnoresExcessiveRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient("key", srv.URL)
+	require.NoError(t, err)
+
+	// The 429 is returned instead of retried, and does not pause the Client.
+	_, err = do[Tag](context.Background(), c, apiRequest{method: http.MethodGet, endpoint: tagEndpoint})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = do[Tag](ctx, c, apiRequest{method: http.MethodGet, endpoint: tagEndpoint})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempts.Load())
+}
@@ -2,7 +2,6 @@ package one_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -19,138 +18,73 @@ type Tag struct {
 
 const tagEndpoint = "flexMetal/tags"
 
-// TagResponse can contain Tag in case of a 200 response
-// or an ErrorResponse
-type TagResponse struct {
-	ErrorResponse *ErrorResponse
-	Tag           *Tag
-}
-
-func (c *Client) CreateTag(ctx context.Context, name string) (*TagResponse, error) {
-	req := Tag{Tag: name}
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := c.callAPI(ctx, http.MethodPost, tagEndpoint, "", body, nil)
+func (c *Client) CreateTag(ctx context.Context, name string) (*Tag, error) {
+	tag, err := do[Tag](ctx, c, apiRequest{
+		method:   http.MethodPost,
+		endpoint: tagEndpoint,
+		body:     Tag{Tag: name},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error on calling create tag api: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var response TagResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
 
-	var tagResp []Tag
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&tagResp); err != nil {
-		return nil, fmt.Errorf("could not decode response: %w", err)
-	}
-
-	if len(tagResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
-
-	response.Tag = &tagResp[0]
-	return &response, nil
+	return tag, nil
 }
 
-func (c *Client) UpdateTag(ctx context.Context, oldName, newName string) (*TagResponse, error) {
-	req := Tag{Tag: newName}
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := c.callAPI(ctx, http.MethodPut, tagEndpoint, oldName, body, nil)
+func (c *Client) UpdateTag(ctx context.Context, oldName, newName string) (*Tag, error) {
+	tag, err := do[Tag](ctx, c, apiRequest{
+		method:   http.MethodPut,
+		endpoint: tagEndpoint,
+		path:     oldName,
+		body:     Tag{Tag: newName},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error calling update tag API: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var response TagResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
-	}
-
-	var tagResp []Tag
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&tagResp); err != nil {
-		return nil, fmt.Errorf("could not decode response: %w", err)
-	}
-
-	if len(tagResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
 
-	response.Tag = &tagResp[0]
-	return &response, nil
+	return tag, nil
 }
 
 func (c *Client) DeleteTag(ctx context.Context, name string) error {
-	resp, err := c.callAPI(ctx, http.MethodDelete, tagEndpoint, name, nil, nil)
+	err := doNoContent(ctx, c, apiRequest{
+		method:   http.MethodDelete,
+		endpoint: tagEndpoint,
+		path:     name,
+	})
 	if err != nil {
 		return fmt.Errorf("error calling delete tag API: %w", err)
 	}
-	defer resp.Body.Close()
 
 	return nil
 }
 
-func (c *Client) GetTag(ctx context.Context, name string) (*TagResponse, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, tagEndpoint, name, nil, nil)
+func (c *Client) GetTag(ctx context.Context, name string) (*Tag, error) {
+	tag, err := do[Tag](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: tagEndpoint,
+		path:     name,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error calling delete tag API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var response TagResponse
-	if resp.StatusCode >= 400 {
-		response.ErrorResponse = decodeErrResponse(resp)
-		return &response, nil
+		return nil, fmt.Errorf("error calling get tag API: %w", err)
 	}
 
-	var tagResp []Tag
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&tagResp); err != nil {
-		return nil, fmt.Errorf("could not decode response: %w", err)
-	}
-
-	if len(tagResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
-
-	response.Tag = &tagResp[0]
-	return &response, nil
+	return tag, nil
 }
 
 func (c *Client) ListTags(ctx context.Context, name string) ([]Tag, error) {
-	resp, err := c.callAPI(ctx, http.MethodGet, tagEndpoint, "", nil, nil)
+	tags, err := collect(paginate[Tag](ctx, c, apiRequest{
+		method:   http.MethodGet,
+		endpoint: tagEndpoint,
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("error on calling list tags api: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var tagResp []Tag
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&tagResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
-
-	if len(tagResp) == 0 {
-		return nil, fmt.Errorf("unexpected empty response")
-	}
 
 	if name != "" {
-		tagResp = filterByName(name, tagResp)
+		tags = filterByName(name, tags)
 	}
 
-	return tagResp, nil
+	return tags, nil
 }
 
 func filterByName(name string, tags []Tag) []Tag {
@@ -0,0 +1,101 @@
+package one_api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig configures the HTTP transport a Client creates once and
+// shares between all of its requests, so keep-alive connections are reused.
+type TransportConfig struct {
+	// MaxIdleConns is the size of the keep-alive connection pool.
+	MaxIdleConns int
+	// ProxyURL is the proxy every request is sent through. When empty, the
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.
+	ProxyURL string
+	// CACertFile is the path to a PEM bundle of CA certificates trusted in
+	// addition to the system ones, e.g. for a TLS-intercepting proxy.
+	CACertFile string
+	// RequestTimeout bounds a single request attempt, including reading the
+	// response body. Zero means no timeout.
+	RequestTimeout time.Duration
+}
+
+// DefaultTransportConfig is used by NewClient unless WithTransport is passed.
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConns:   10,
+	RequestTimeout: 90 * time.Second,
+}
+
+// WithTransport sets the HTTP transport settings of the Client.
+func WithTransport(cfg TransportConfig) Option {
+	return func(c *Client) {
+		c.transport = cfg
+	}
+}
+
+// newHTTPClient builds the long-lived http.Client used for every request.
+// When cassette is set, requests are recorded to or replayed from it.
+func newHTTPClient(cfg TransportConfig, cassette *Cassette) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.MaxIdleConns > 0 {
+		// All requests go to the same host, so the pool is not split per host.
+		transport.MaxIdleConns = cfg.MaxIdleConns
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConns
+	}
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse proxy url %s: %w", cfg.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.CACertFile != "" {
+		pool, err := certPool(cfg.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	var next http.RoundTripper = transport
+	if cassette != nil {
+		next = cassette.roundTripper(transport)
+	}
+
+	return &http.Client{
+		Transport: &loggingRoundTripper{next: next},
+		Timeout:   cfg.RequestTimeout,
+	}, nil
+}
+
+// certPool returns the system certificate pool extended with the PEM
+// certificates found in caCertFile.
+func certPool(caCertFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA certificate file %s: %w", caCertFile, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in %s", caCertFile)
+	}
+
+	return pool, nil
+}
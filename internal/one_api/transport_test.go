@@ -0,0 +1,53 @@
+package one_api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReusesConnections(t *testing.T) {
+	t.Parallel()
+
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient("key", srv.URL)
+	require.NoError(t, err)
+
+	for range 5 {
+		_, err := c.ListLocations(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(1), conns.Load())
+}
+
+func TestNewClientTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient("key", "", WithTransport(TransportConfig{
+		CACertFile: filepath.Join(t.TempDir(), "missing.pem"),
+	}))
+	require.ErrorContains(t, err, "could not read CA certificate file")
+
+	_, err = NewClient("key", "", WithTransport(TransportConfig{
+		ProxyURL: "http://proxy example.com",
+	}))
+	require.ErrorContains(t, err, "could not parse proxy url")
+}
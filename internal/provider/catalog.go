@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// flexvmSites are the sites FlexVM Clouds can be created in. The API has no
+// endpoint listing them.
+var flexvmSites = []string{"frmtl1", "camtr6"}
+
+// catalog caches the names of the things resources refer to by name, e.g.
+// FlexVM images, so that plans can be checked without fetching them for
+// every resource. There is one per configured provider, in its providerData.
+type catalog struct {
+	flexvmImages           cachedNames
+	flexvmInstanceTypes    cachedNames
+	flexmetalInstanceTypes cachedNames
+	flexmetalOSSlugs       cachedNames
+
+	// flexvmInstanceTypeSpecs holds the FlexVM instance types themselves,
+	// for the resources they require.
+	flexvmInstanceTypeSpecs cached[[]one_api.FlexvmInstanceType]
+
+	// operatingSystems holds all operating systems, including the ones that
+	// cannot be installed on FlexMetal servers.
+	operatingSystems cached[[]one_api.OperatingSystem]
+
+	// flexvmCloudCapacities holds the capacity of the FlexVM Clouds when the
+	// run starts, which the VMs planned by the run are checked against.
+	flexvmCloudCapacities cached[[]one_api.FlexvmCloudCapacity]
+	plannedFlexvmVMs      plannedSet[flexvmCapacityKey]
+
+	// flexmetalQuotas holds the quota usage when the run starts, which the
+	// servers planned by the run are counted against.
+	flexmetalQuotas         cached[[]one_api.ServerQuotaUsage]
+	plannedFlexmetalServers plannedSet[flexmetalQuotaKey]
+}
+
+// newCatalog returns an empty catalog loading from client.
+func newCatalog(client *one_api.Client) *catalog {
+	c := &catalog{
+		flexvmImages: cachedNames{load: func(ctx context.Context) ([]string, error) {
+			images, err := client.FlexvmListImages(ctx)
+			return namesOf(images, func(image one_api.FlexvmImage) string { return image.Name }), err
+		}},
+		flexvmInstanceTypeSpecs: cached[[]one_api.FlexvmInstanceType]{load: client.FlexvmListInstanceTypes},
+		operatingSystems:        cached[[]one_api.OperatingSystem]{load: client.ListOperatingSystems},
+		flexvmCloudCapacities:   cached[[]one_api.FlexvmCloudCapacity]{load: client.FlexvmListCloudCapacities},
+		flexmetalQuotas:         cached[[]one_api.ServerQuotaUsage]{load: client.ListQuotaUsage},
+		flexmetalInstanceTypes: cachedNames{load: func(ctx context.Context) ([]string, error) {
+			locations, err := client.ListLocations(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			var names []string
+			for _, location := range locations {
+				instanceTypes, err := client.ListInstanceTypes(ctx, location.ID)
+				if err != nil {
+					return nil, err
+				}
+				names = append(names, namesOf(instanceTypes, func(it one_api.InstanceType) string { return it.Name })...)
+			}
+			slices.Sort(names)
+			return slices.Compact(names), nil
+		}},
+	}
+
+	c.flexvmInstanceTypes.load = func(ctx context.Context) ([]string, error) {
+		instanceTypes, err := c.flexvmInstanceTypeSpecs.get(ctx)
+		return namesOf(instanceTypes, func(it one_api.FlexvmInstanceType) string { return it.Name }), err
+	}
+
+	c.flexmetalOSSlugs.load = func(ctx context.Context) ([]string, error) {
+		operatingSystems, err := c.operatingSystems.get(ctx)
+		operatingSystems = slices.DeleteFunc(slices.Clone(operatingSystems), func(os one_api.OperatingSystem) bool {
+			return !os.UsableForFlexMetal()
+		})
+		return namesOf(operatingSystems, func(os one_api.OperatingSystem) string { return os.Slug }), err
+	}
+
+	return c
+}
+
+// cached lazily loads a value. Unlike with sync.Once, a failed load is
+// retried on the next call.
+type cached[T any] struct {
+	load func(context.Context) (T, error)
+
+	mu     sync.Mutex
+	value  T
+	loaded bool
+}
+
+// cachedNames lazily loads a list of names.
+type cachedNames = cached[[]string]
+
+func (c *cached[T]) get(ctx context.Context) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded {
+		return c.value, nil
+	}
+
+	value, err := c.load(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	c.value, c.loaded = value, true
+
+	return c.value, nil
+}
+
+// plannedSet collects the resources planned by a run that share a limited
+// resource, e.g. the FlexVM VMs of an instance type in a Cloud, so that they
+// are checked against it together. Resources are identified by
+// plannedResourceID, so planning the same resource again does not count it
+// twice.
+type plannedSet[K comparable] struct {
+	mu        sync.Mutex
+	resources map[K]map[string]bool
+}
+
+// add records that resource is planned for key, and returns the number of
+// resources planned for key so far.
+func (p *plannedSet[K]) add(key K, resource string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resources == nil {
+		p.resources = map[K]map[string]bool{}
+	}
+	if p.resources[key] == nil {
+		p.resources[key] = map[string]bool{}
+	}
+	p.resources[key][resource] = true
+
+	return len(p.resources[key])
+}
+
+// plannedResourceID identifies a planned resource for a plannedSet: by id
+// once it exists, else by its name, else by its whole planned value.
+func plannedResourceID(req resource.ModifyPlanRequest, id, name types.String) string {
+	switch {
+	case !id.IsNull() && !id.IsUnknown():
+		return "id:" + id.ValueString()
+	case !name.IsNull() && !name.IsUnknown():
+		return "name:" + name.ValueString()
+	default:
+		return "plan:" + req.Plan.Raw.String()
+	}
+}
+
+func namesOf[T any](items []T, name func(T) string) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, name(item))
+	}
+	return names
+}
+
+// validatePlannedCatalogName validates the planned value of attrPath with
+// validateCatalogName when the resource is created or the value changes.
+// Existing resources are not checked, so that a name removed from the
+// catalog later on does not fail every plan.
+func validatePlannedCatalogName(ctx context.Context, req resource.ModifyPlanRequest, attrPath path.Path, names *cachedNames, kind, hint string, diags *diag.Diagnostics) {
+	var planned, current types.String
+	diags.Append(req.Plan.GetAttribute(ctx, attrPath, &planned)...)
+	if !req.State.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, attrPath, &current)...)
+	}
+	if diags.HasError() || planned.Equal(current) {
+		return
+	}
+
+	validateCatalogName(ctx, names, planned, attrPath, kind, hint, diags)
+}
+
+// validateCatalogName adds an error to diags when value is not one of the
+// names in names, suggesting the closest valid name. Null and unknown values
+// are not checked. When the names cannot be loaded, the check is skipped with
+// a warning: the API still rejects invalid names when applying.
+func validateCatalogName(ctx context.Context, names *cachedNames, value types.String, attrPath path.Path, kind, hint string, diags *diag.Diagnostics) {
+	if value.IsNull() || value.IsUnknown() {
+		return
+	}
+
+	valid, err := names.get(ctx)
+	if err != nil {
+		diags.AddAttributeWarning(attrPath,
+			fmt.Sprintf("Unable to validate %s", kind),
+			fmt.Sprintf("The list of valid values could not be fetched, so %q is not checked before applying.\nError: %v",
+				value.ValueString(), err),
+		)
+		return
+	}
+
+	if msg, ok := checkName(value.ValueString(), valid, kind, hint); !ok {
+		diags.AddAttributeError(attrPath, fmt.Sprintf("Invalid %s", kind), msg)
+	}
+}
+
+// checkName reports whether name is one of valid. If not, it also returns an
+// error message suggesting the closest valid name, if any is close enough.
+func checkName(name string, valid []string, kind, hint string) (string, bool) {
+	if slices.Contains(valid, name) {
+		return "", true
+	}
+
+	msg := fmt.Sprintf("%q is not a valid %s.", name, kind)
+	if closest := closestName(name, valid); closest != "" {
+		msg += fmt.Sprintf(" Did you mean %q?", closest)
+	}
+	if hint != "" {
+		msg += " " + hint
+	}
+
+	return msg, false
+}
+
+// closestName returns the name in names with the smallest edit distance to
+// name, or "" when none is within a third of the length of name.
+func closestName(name string, names []string) string {
+	closest, best := "", len(name)/3+1
+	for _, candidate := range names {
+		if d := editDistance(name, candidate); d < best {
+			closest, best = candidate, d
+		}
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between a and b, comparing
+// bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
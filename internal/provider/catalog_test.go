@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckName(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{"ubuntu-2204-server-amd64", "ubuntu-2404-server-amd64", "debian-12-server-amd64"}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantOK  bool
+		wantMsg string
+	}{
+		{
+			name:   "valid name",
+			value:  "debian-12-server-amd64",
+			wantOK: true,
+		},
+		{
+			name:    "typo suggests the closest name",
+			value:   "ubuntu-2404-server-amd46",
+			wantMsg: `"ubuntu-2404-server-amd46" is not a valid image. Did you mean "ubuntu-2404-server-amd64"? Use the data source.`,
+		},
+		{
+			name:    "unrelated name has no suggestion",
+			value:   "windows",
+			wantMsg: `"windows" is not a valid image. Use the data source.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, ok := checkName(tt.value, valid, "image", "Use the data source.")
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantMsg, msg)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, editDistance("vm.4c.8g", "vm.4c.8g"))
+	require.Equal(t, 1, editDistance("vm.4c.8g", "vm.4c.8gb"))
+	require.Equal(t, 2, editDistance("amd46", "amd64"))
+	require.Equal(t, 6, editDistance("", "frmtl1"))
+}
+
+func TestCachedNamesRetriesFailedLoads(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	c := cachedNames{load: func(ctx context.Context) ([]string, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return []string{"frmtl1"}, nil
+	}}
+
+	_, err := c.get(context.Background())
+	require.Error(t, err)
+
+	for range 2 {
+		names, err := c.get(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"frmtl1"}, names)
+	}
+	require.Equal(t, 2, calls)
+}
@@ -11,29 +11,55 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-// clientFromProviderData extracts the *one_api.Client that the provider passes
-// to resources and data sources via ProviderData. It is shared by the Configure
+// providerData is what the configured provider passes to resources, data
+// sources and ephemeral resources via ProviderData: the API client, and the
+// catalog caching what their plans are checked against. Both live as long as
+// the configured provider.
+type providerData struct {
+	client  *one_api.Client
+	catalog *catalog
+}
+
+// newProviderData returns the provider data of client, with an empty catalog.
+func newProviderData(client *one_api.Client) *providerData {
+	return &providerData{client: client, catalog: newCatalog(client)}
+}
+
+// fromProviderData extracts the *providerData that the provider passes to
+// resources and data sources via ProviderData. It is shared by the Configure
 // methods of every resource and data source.
 //
-// providerData is nil when Terraform calls Configure before the provider itself
+// raw is nil when Terraform calls Configure before the provider itself
 // has been configured; in that case it returns nil without adding a diagnostic,
 // and Configure is called again later with a populated value. A non-nil value of
 // an unexpected type is reported as an error.
-func clientFromProviderData(providerData any, diags *diag.Diagnostics) *one_api.Client {
-	if providerData == nil {
+func fromProviderData(raw any, diags *diag.Diagnostics) *providerData {
+	if raw == nil {
 		return nil
 	}
 
-	client, ok := providerData.(*one_api.Client)
+	data, ok := raw.(*providerData)
 	if !ok {
 		diags.AddError(
 			"Unexpected Configure Type",
-			fmt.Sprintf("Expected *one_api.Client, got: %T. Please report this issue to the provider developers.", providerData),
+			fmt.Sprintf("Expected *provider.providerData, got: %T. Please report this issue to the provider developers.", raw),
 		)
 		return nil
 	}
 
-	return client
+	return data
+}
+
+// clientFromProviderData extracts the *one_api.Client from ProviderData, like
+// fromProviderData, for the resources and data sources that only need the
+// client.
+func clientFromProviderData(providerData any, diags *diag.Diagnostics) *one_api.Client {
+	data := fromProviderData(providerData, diags)
+	if data == nil {
+		return nil
+	}
+
+	return data.client
 }
 
 // emptyStringAsNull is a plan modifier that treats an explicitly empty string
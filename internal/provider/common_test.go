@@ -4,8 +4,14 @@ import (
 	"context"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEmptyStringAsNull(t *testing.T) {
@@ -55,3 +61,33 @@ func TestEmptyStringAsNull(t *testing.T) {
 		})
 	}
 }
+
+// modifyPlanRequest returns the ModifyPlanRequest of r planning the given
+// attributes. The resource is created when state is nil, and updated from
+// state otherwise. Attributes not given are null.
+func modifyPlanRequest(t *testing.T, r resource.Resource, plan, state map[string]any) resource.ModifyPlanRequest {
+	t.Helper()
+
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError())
+	null := tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)
+
+	set := func(attrs map[string]any, set func(context.Context, path.Path, any) diag.Diagnostics) {
+		for name, value := range attrs {
+			require.False(t, set(ctx, path.Root(name), value).HasError(), name)
+		}
+	}
+
+	req := resource.ModifyPlanRequest{
+		Plan:  tfsdk.Plan{Schema: schemaResp.Schema, Raw: null},
+		State: tfsdk.State{Schema: schemaResp.Schema, Raw: null},
+	}
+	set(plan, req.Plan.SetAttribute)
+	if state != nil {
+		set(state, req.State.SetAttribute)
+	}
+
+	return req
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"unicode"
@@ -11,9 +12,18 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 )
 
-func AddErrorResponseToDiags(message string, resp *one_api.ErrorResponse, diags *diag.Diagnostics) {
+// AddErrorResponseToDiags adds err to diags under the given summary. When err
+// is an *one_api.APIError, the status code, error code and messages returned
+// by the API are listed in the details.
+func AddErrorResponseToDiags(message string, err error, diags *diag.Diagnostics) {
 	summary := message
 
+	var resp *one_api.APIError
+	if !errors.As(err, &resp) {
+		diags.AddError(summary, firstUpper(err.Error()))
+		return
+	}
+
 	details := fmt.Sprintf("Status code: %d\n", resp.StatusCode)
 	details += fmt.Sprintf("Code: %d\n", resp.ErrorCode)
 	if len(resp.Errors) == 0 { // for duplicated tags, the error is not in resp.Errors but resp.ErrorMessage
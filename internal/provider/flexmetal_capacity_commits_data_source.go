@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexmetalCapacityCommitsDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexmetalCapacityCommitsDataSource)(nil)
+)
+
+func NewFlexmetalCapacityCommitsDataSource() datasource.DataSource {
+	return &flexmetalCapacityCommitsDataSource{}
+}
+
+// flexmetalCapacityCommitsDataSource reads the committed FlexMetal capacity
+// of the account and how much of it is in use.
+type flexmetalCapacityCommitsDataSource struct {
+	client *one_api.Client
+}
+
+type flexmetalCapacityCommitsDataSourceModel struct {
+	Location types.String `tfsdk:"location"`
+	Commits  types.List   `tfsdk:"commits"`
+}
+
+var flexmetalCapacityCommitObjectAttrTypes = map[string]attr.Type{
+	"location":         types.StringType,
+	"location_id":      types.Int64Type,
+	"instance_type":    types.StringType,
+	"instance_type_id": types.Int64Type,
+	"capacity":         types.Int64Type,
+	"in_use":           types.Int64Type,
+	"available":        types.Int64Type,
+}
+
+func (d *flexmetalCapacityCommitsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexmetalCapacityCommitsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexmetal_capacity_commits"
+}
+
+func (d *flexmetalCapacityCommitsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the FlexMetal capacity committed to by your i3D.net account and how much of it is in use, " +
+			"per location and instance type.",
+		Attributes: map[string]schema.Attribute{
+			"location": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include the commits in the location with this name, e.g. `EU: Rotterdam`.",
+			},
+			"commits": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The committed capacity, by location and instance type.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"location": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the location.",
+						},
+						"location_id": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "ID of the location.",
+						},
+						"instance_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the instance type.",
+						},
+						"instance_type_id": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "ID of the instance type.",
+						},
+						"capacity": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of committed servers.",
+						},
+						"in_use": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of committed servers in use.",
+						},
+						"available": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of committed servers still available. Always `capacity` minus `in_use`, and never negative.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexmetalCapacityCommitsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexmetalCapacityCommitsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	capacityCommits, err := d.client.ListCapacityCommits(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error reading FlexMetal capacity commits", err, &resp.Diagnostics)
+		return
+	}
+
+	commitValues := []attr.Value{}
+	for _, locationCommits := range capacityCommits {
+		if !data.Location.IsNull() && locationCommits.Location.Name != data.Location.ValueString() {
+			continue
+		}
+		for _, commit := range locationCommits.Commits {
+			obj, diags := types.ObjectValue(flexmetalCapacityCommitObjectAttrTypes, map[string]attr.Value{
+				"location":         types.StringValue(locationCommits.Location.Name),
+				"location_id":      types.Int64Value(int64(locationCommits.Location.ID)),
+				"instance_type":    types.StringValue(commit.InstanceType.Name),
+				"instance_type_id": types.Int64Value(int64(commit.InstanceType.ID)),
+				"capacity":         types.Int64Value(int64(commit.Capacity)),
+				"in_use":           types.Int64Value(int64(commit.InUse)),
+				"available":        types.Int64Value(int64(max(commit.Capacity-commit.InUse, 0))),
+			})
+			resp.Diagnostics.Append(diags...)
+			commitValues = append(commitValues, obj)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	commitsList, diags := types.ListValue(types.ObjectType{AttrTypes: flexmetalCapacityCommitObjectAttrTypes}, commitValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Commits = commitsList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
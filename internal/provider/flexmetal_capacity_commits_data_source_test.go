@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexmetalCapacityCommitsDataSource(t *testing.T) {
+	apiclient := newOneAPIClient(t, resourceNsFlexmetal)
+
+	capacityCommits, err := apiclient.ListCapacityCommits(context.Background())
+	require.NoError(t, err)
+
+	nrOfCommits := 0
+	for _, locationCommits := range capacityCommits {
+		nrOfCommits += len(locationCommits.Commits)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_capacity_commits" "all" {
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Commits from data source should match commits from One API
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_capacity_commits.all", "commits.#", strconv.Itoa(nrOfCommits)),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource                     = (*flexmetalInstanceTypesDataSource)(nil)
+	_ datasource.DataSourceWithConfigure        = (*flexmetalInstanceTypesDataSource)(nil)
+	_ datasource.DataSourceWithConfigValidators = (*flexmetalInstanceTypesDataSource)(nil)
+)
+
+func NewFlexmetalInstanceTypesDataSource() datasource.DataSource {
+	return &flexmetalInstanceTypesDataSource{}
+}
+
+// flexmetalInstanceTypesDataSource lists the FlexMetal instance types of a
+// location, optionally filtered and narrowed down to the smallest one.
+type flexmetalInstanceTypesDataSource struct {
+	client *one_api.Client
+}
+
+type flexmetalInstanceTypesDataSourceModel struct {
+	Location      types.String `tfsdk:"location"`
+	LocationID    types.Int64  `tfsdk:"location_id"`
+	NameRegex     types.String `tfsdk:"name_regex"`
+	MinCores      types.Int64  `tfsdk:"min_cores"`
+	MinMemory     types.Int64  `tfsdk:"min_memory"`
+	MinStorage    types.Int64  `tfsdk:"min_storage"`
+	Smallest      types.Bool   `tfsdk:"smallest"`
+	InstanceTypes types.List   `tfsdk:"instance_types"`
+}
+
+var flexmetalInstanceTypeObjectAttrTypes = map[string]attr.Type{
+	"id":              types.Int64Type,
+	"name":            types.StringType,
+	"sockets":         types.Int64Type,
+	"cores":           types.Int64Type,
+	"memory":          types.Int64Type,
+	"memory_type":     types.StringType,
+	"storage":         types.Int64Type,
+	"storage_type":    types.StringType,
+	"generation_name": types.StringType,
+}
+
+func (d *flexmetalInstanceTypesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexmetalInstanceTypesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexmetal_instance_types"
+}
+
+func (d *flexmetalInstanceTypesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the FlexMetal instance types available in a location. Use the filters to find a valid " +
+			"`instance_type` for `i3dnet_flexmetal_server` without hard-coding it. The API does not expose the network " +
+			"interfaces or the stock of instance types, so those are not returned.",
+		Attributes: map[string]schema.Attribute{
+			"location": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Name of the location, as used by the `location` of `i3dnet_flexmetal_server`. " +
+					"Exactly one of `location` and `location_id` must be set.",
+			},
+			"location_id": schema.Int64Attribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "ID of the location.",
+			},
+			"name_regex": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types whose name matches this regular expression, e.g. `^bm9\\.`.",
+			},
+			"min_cores": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with at least this many CPU cores.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"min_memory": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with at least this much memory, in GB.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"min_storage": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with at least this much storage, in GB.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"smallest": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Only return the smallest of the matching instance types, comparing cores, " +
+					"then memory, then storage. An error is returned when no instance type matches.",
+			},
+			"instance_types": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching instance types, sorted from smallest to largest.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Instance type ID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Instance type name, to use as `instance_type` of `i3dnet_flexmetal_server`.",
+						},
+						"sockets": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of CPU sockets.",
+						},
+						"cores": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of CPU cores.",
+						},
+						"memory": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Memory in GB.",
+						},
+						"memory_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Memory type, e.g. `DDR5`.",
+						},
+						"storage": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Storage size in GB.",
+						},
+						"storage_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Storage type, e.g. `NVMe`.",
+						},
+						"generation_name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Hardware generation of the instance type, e.g. `bm9`.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexmetalInstanceTypesDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("location"),
+			path.MatchRoot("location_id"),
+		),
+	}
+}
+
+func (d *flexmetalInstanceTypesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexmetalInstanceTypesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := flexmetalInstanceTypeFilter{
+		minCores:   int(data.MinCores.ValueInt64()),
+		minMemory:  int(data.MinMemory.ValueInt64()),
+		minStorage: int(data.MinStorage.ValueInt64()),
+		smallest:   data.Smallest.ValueBool(),
+	}
+	filter.nameRegex = compileNameRegex(data.NameRegex, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	location := d.findLocation(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	instanceTypes, err := d.client.ListInstanceTypes(ctx, location.ID)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexMetal instance types", err, &resp.Diagnostics)
+		return
+	}
+
+	instanceTypes = filter.apply(instanceTypes)
+	if filter.smallest && len(instanceTypes) == 0 {
+		resp.Diagnostics.AddError(
+			"No FlexMetal instance type found",
+			fmt.Sprintf("No FlexMetal instance type in %s matches the filters. Remove smallest to get an empty list instead.",
+				location.Name),
+		)
+		return
+	}
+
+	instanceTypeValues := make([]attr.Value, 0, len(instanceTypes))
+	for _, it := range instanceTypes {
+		obj, diags := types.ObjectValue(flexmetalInstanceTypeObjectAttrTypes, map[string]attr.Value{
+			"id":              types.Int64Value(int64(it.ID)),
+			"name":            types.StringValue(it.Name),
+			"sockets":         types.Int64Value(int64(it.Sockets)),
+			"cores":           types.Int64Value(int64(it.Cores)),
+			"memory":          types.Int64Value(int64(it.Memory)),
+			"memory_type":     types.StringValue(it.MemoryType),
+			"storage":         types.Int64Value(int64(it.Storage)),
+			"storage_type":    types.StringValue(it.StorageType),
+			"generation_name": types.StringValue(it.GenerationName),
+		})
+		resp.Diagnostics.Append(diags...)
+		instanceTypeValues = append(instanceTypeValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	instanceTypesList, diags := types.ListValue(types.ObjectType{AttrTypes: flexmetalInstanceTypeObjectAttrTypes}, instanceTypeValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Location = types.StringValue(location.Name)
+	data.LocationID = types.Int64Value(int64(location.ID))
+	data.InstanceTypes = instanceTypesList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// findLocation returns the location selected by the location or location_id
+// of data, suggesting the closest location name when there is none.
+func (d *flexmetalInstanceTypesDataSource) findLocation(ctx context.Context, data flexmetalInstanceTypesDataSourceModel, diags *diag.Diagnostics) *one_api.Location {
+	locations, err := d.client.ListLocations(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexMetal locations", err, diags)
+		return nil
+	}
+
+	if !data.LocationID.IsNull() {
+		id := int(data.LocationID.ValueInt64())
+		if i := slices.IndexFunc(locations, func(l one_api.Location) bool { return l.ID == id }); i >= 0 {
+			return &locations[i]
+		}
+		diags.AddAttributeError(path.Root("location_id"), "FlexMetal location not found",
+			fmt.Sprintf("No FlexMetal location found with id %d", id))
+		return nil
+	}
+
+	name := data.Location.ValueString()
+	if i := slices.IndexFunc(locations, func(l one_api.Location) bool { return l.Name == name }); i >= 0 {
+		return &locations[i]
+	}
+	msg, _ := checkName(name, namesOf(locations, func(l one_api.Location) string { return l.Name }), "location",
+		"Use the i3dnet_locations data source to list the available locations.")
+	diags.AddAttributeError(path.Root("location"), "FlexMetal location not found", msg)
+	return nil
+}
+
+// flexmetalInstanceTypeFilter selects instance types by the filters of the
+// FlexMetal instance types data source.
+type flexmetalInstanceTypeFilter struct {
+	nameRegex  *regexp.Regexp
+	minCores   int
+	minMemory  int
+	minStorage int
+	smallest   bool
+}
+
+// apply returns the instance types matching f, sorted from smallest to
+// largest, or only the smallest one when f.smallest is set.
+func (f flexmetalInstanceTypeFilter) apply(instanceTypes []one_api.InstanceType) []one_api.InstanceType {
+	matched := slices.DeleteFunc(slices.Clone(instanceTypes), func(it one_api.InstanceType) bool {
+		switch {
+		case f.nameRegex != nil && !f.nameRegex.MatchString(it.Name):
+			return true
+		case it.Cores < f.minCores || it.Memory < f.minMemory || it.Storage < f.minStorage:
+			return true
+		}
+		return false
+	})
+
+	slices.SortFunc(matched, func(a, b one_api.InstanceType) int {
+		return cmp.Or(
+			cmp.Compare(a.Cores, b.Cores),
+			cmp.Compare(a.Memory, b.Memory),
+			cmp.Compare(a.Storage, b.Storage),
+			compareNatural(a.Name, b.Name),
+		)
+	})
+
+	if f.smallest && len(matched) > 0 {
+		return matched[:1]
+	}
+
+	return matched
+}
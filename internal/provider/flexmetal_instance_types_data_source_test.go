@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexmetalInstanceTypesDataSource(t *testing.T) {
+	apiclient := newOneAPIClient(t, resourceNsFlexmetal)
+
+	locations, err := apiclient.ListLocations(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, locations)
+
+	location := locations[0]
+	instanceTypes, err := apiclient.ListInstanceTypes(context.Background(), location.ID)
+	require.NoError(t, err)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_instance_types" "by_name" {
+  location = ` + strconv.Quote(location.Name) + `
+}
+
+data "i3dnet_flexmetal_instance_types" "by_id" {
+  location_id = ` + strconv.Itoa(location.ID) + `
+  smallest    = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Instance types from data source should match instance types from One API
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_instance_types.by_name", "instance_types.#", strconv.Itoa(len(instanceTypes))),
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_instance_types.by_name", "location_id", strconv.Itoa(location.ID)),
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_instance_types.by_id", "location", location.Name),
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_instance_types.by_id", "instance_types.#", "1"),
+				),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_instance_types" "none" {
+  location_id = ` + strconv.Itoa(location.ID) + `
+  min_cores   = 100000
+  smallest    = true
+}
+`,
+				ExpectError: regexp.MustCompile(`No FlexMetal instance type found`),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_instance_types" "missing" {
+  location = "terraform-acceptance-no-such-location"
+}
+`,
+				ExpectError: regexp.MustCompile(`FlexMetal location not found`),
+			},
+		},
+	})
+}
+
+func TestFlexmetalInstanceTypeFilter(t *testing.T) {
+	t.Parallel()
+
+	instanceTypes := []one_api.InstanceType{
+		{Name: "bm9.hmm.gpu.4rtx4000.64", Cores: 64, Memory: 1024, Storage: 7680},
+		{Name: "bm9.std.16", Cores: 16, Memory: 64, Storage: 960},
+		{Name: "bm7.std.16", Cores: 16, Memory: 64, Storage: 480},
+		{Name: "bm7.std.8", Cores: 8, Memory: 32, Storage: 480},
+	}
+
+	tests := []struct {
+		name   string
+		filter flexmetalInstanceTypeFilter
+		want   []string
+	}{
+		{
+			name:   "no filter sorts by size",
+			filter: flexmetalInstanceTypeFilter{},
+			want:   []string{"bm7.std.8", "bm7.std.16", "bm9.std.16", "bm9.hmm.gpu.4rtx4000.64"},
+		},
+		{
+			name:   "minimum sizes",
+			filter: flexmetalInstanceTypeFilter{minCores: 16, minStorage: 900},
+			want:   []string{"bm9.std.16", "bm9.hmm.gpu.4rtx4000.64"},
+		},
+		{
+			name:   "smallest",
+			filter: flexmetalInstanceTypeFilter{minMemory: 64, smallest: true},
+			want:   []string{"bm7.std.16"},
+		},
+		{
+			name:   "name regex",
+			filter: flexmetalInstanceTypeFilter{nameRegex: regexp.MustCompile(`^bm9\.`)},
+			want:   []string{"bm9.std.16", "bm9.hmm.gpu.4rtx4000.64"},
+		},
+		{
+			name:   "smallest without match",
+			filter: flexmetalInstanceTypeFilter{minCores: 128, smallest: true},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			names := []string{}
+			for _, it := range tt.filter.apply(instanceTypes) {
+				names = append(names, it.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
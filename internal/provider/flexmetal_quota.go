@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Values of quota_check of i3dnet_flexmetal_server.
+const (
+	flexmetalQuotaCheckWarn  = "warn"
+	flexmetalQuotaCheckError = "error"
+)
+
+// flexmetalQuotaKey identifies the quota a FlexMetal server counts towards.
+// The contract ID is empty for the on demand quota.
+type flexmetalQuotaKey struct {
+	location     string
+	instanceType string
+	contractID   string
+}
+
+func (k flexmetalQuotaKey) String() string {
+	if k.contractID == "" {
+		return fmt.Sprintf("on demand quota of %s servers in %s", k.instanceType, k.location)
+	}
+	return fmt.Sprintf("quota of %s servers in %s under contract %s", k.instanceType, k.location, k.contractID)
+}
+
+// flexmetalQuotaKeyOf returns the quota the server planned or stored in data
+// counts towards.
+func flexmetalQuotaKeyOf(data FlexmetalServerModel) flexmetalQuotaKey {
+	return flexmetalQuotaKey{
+		location:     data.Location.ValueString(),
+		instanceType: data.InstanceType.ValueString(),
+		contractID:   data.ContractId.ValueString(),
+	}
+}
+
+// flexmetalServerReplaced reports whether the plan of an existing server
+// replaces it, i.e. changes any of flexmetalServerRequiresReplace. Changes to
+// other attributes, such as contract_id, are applied in place and order no
+// new server.
+func flexmetalServerReplaced(ctx context.Context, req resource.ModifyPlanRequest) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	for _, name := range flexmetalServerRequiresReplace {
+		var planned, current types.String
+		diags.Append(req.Plan.GetAttribute(ctx, path.Root(name), &planned)...)
+		diags.Append(req.State.GetAttribute(ctx, path.Root(name), &current)...)
+		if !diags.HasError() && !planned.Equal(current) {
+			return true, diags
+		}
+	}
+	return false, diags
+}
+
+// checkFlexmetalServerQuota reports, as set by quota_check, when a planned
+// server does not fit in the remaining quota of its location and instance
+// type, so that the plan fails rather than the order.
+//
+// Every server ordered by the plan is counted, including the ones without
+// quota_check, and compared to the quota usage fetched once per run. Servers
+// are identified by UUID or name, so planning one again does not count it
+// twice. As servers are counted in the order they are planned, only the
+// servers exceeding the quota are reported.
+func checkFlexmetalServerQuota(ctx context.Context, c *catalog, req resource.ModifyPlanRequest, diags *diag.Diagnostics) {
+	var plan, state FlexmetalServerModel
+	diags.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		diags.Append(req.State.Get(ctx, &state)...)
+	}
+	if diags.HasError() {
+		return
+	}
+
+	if plan.Location.IsUnknown() || plan.InstanceType.IsUnknown() || plan.ContractId.IsUnknown() {
+		return
+	}
+
+	key := flexmetalQuotaKeyOf(plan)
+	if !req.State.Raw.IsNull() {
+		replaced, d := flexmetalServerReplaced(ctx, req)
+		diags.Append(d...)
+		if diags.HasError() || !replaced || key == flexmetalQuotaKeyOf(state) {
+			// No new server is ordered, or a replaced server is released
+			// before its replacement is ordered, freeing its quota.
+			return
+		}
+	}
+
+	planned := c.plannedFlexmetalServers.add(key, plannedResourceID(req, state.Uuid, plan.Name))
+
+	level := plan.QuotaCheck.ValueString()
+	if level == "" {
+		return
+	}
+	if key.contractID != "" && plan.Overflow.ValueBool() {
+		// The server is ordered even when the commit is used up.
+		return
+	}
+
+	quotas, err := c.flexmetalQuotas.get(ctx)
+	if err != nil {
+		diags.AddAttributeWarning(path.Root("quota_check"), "Unable to check FlexMetal quota",
+			fmt.Sprintf("The quota usage could not be fetched, so the %s is not checked before applying.\nError: %v", key, err),
+		)
+		return
+	}
+
+	report := diags.AddAttributeWarning
+	if level == flexmetalQuotaCheckError {
+		report = diags.AddAttributeError
+	}
+
+	quota, ok := findFlexmetalQuota(quotas, key)
+	if !ok {
+		report(path.Root("instance_type"), "No FlexMetal quota",
+			fmt.Sprintf("Your account has no %s, so ordering the server will likely fail. "+
+				"Use the i3dnet_flexmetal_quota_usage data source to list your quotas.", key))
+		return
+	}
+
+	remaining := remainingQuota(quota)
+	tflog.Debug(ctx, "Checked FlexMetal quota", map[string]any{
+		"location": key.location, "instance_type": key.instanceType, "contract_id": key.contractID,
+		"remaining": remaining, "planned": planned,
+	})
+	if planned <= remaining {
+		return
+	}
+
+	report(path.Root("instance_type"), "Insufficient FlexMetal quota",
+		fmt.Sprintf("This plan orders at least %d servers within the %s, which allows %d more (quota %d, in use %d). "+
+			"Ordering the server will likely fail. Release servers or ask i3D.net to raise the quota.",
+			planned, key, remaining, quota.Quota, quota.Usage))
+}
+
+// findFlexmetalQuota returns the quota identified by key.
+func findFlexmetalQuota(quotas []one_api.ServerQuotaUsage, key flexmetalQuotaKey) (one_api.QuotaUsage, bool) {
+	for _, locationQuotas := range quotas {
+		if locationQuotas.Location.Name != key.location {
+			continue
+		}
+		i := slices.IndexFunc(locationQuotas.QuotaUsage, func(q one_api.QuotaUsage) bool {
+			if q.InstanceType.Name != key.instanceType {
+				return false
+			}
+			if key.contractID == "" {
+				return q.QuotaType == one_api.QuotaTypeOnDemand
+			}
+			return q.QuotaType == one_api.QuotaTypeCommit && q.ContractID == key.contractID
+		})
+		if i >= 0 {
+			return locationQuotas.QuotaUsage[i], true
+		}
+	}
+	return one_api.QuotaUsage{}, false
+}
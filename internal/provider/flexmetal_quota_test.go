@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFlexmetalQuota(t *testing.T) {
+	t.Parallel()
+
+	rotterdam := one_api.LocationPartial{ID: 6, Name: "EU: Rotterdam"}
+	onDemand := one_api.QuotaUsage{
+		InstanceType: one_api.InstanceTypePartial{ID: 1, Name: "bm7.std.8"},
+		QuotaType:    one_api.QuotaTypeOnDemand,
+		Quota:        5,
+		Usage:        1,
+	}
+	commit := one_api.QuotaUsage{
+		InstanceType: one_api.InstanceTypePartial{ID: 1, Name: "bm7.std.8"},
+		QuotaType:    one_api.QuotaTypeCommit,
+		ContractID:   "CONTRACT-123",
+		Quota:        2,
+		Usage:        2,
+	}
+	quotas := []one_api.ServerQuotaUsage{{Location: rotterdam, QuotaUsage: []one_api.QuotaUsage{commit, onDemand}}}
+
+	tests := []struct {
+		name   string
+		key    flexmetalQuotaKey
+		want   one_api.QuotaUsage
+		wantOK bool
+	}{
+		{
+			name:   "on demand",
+			key:    flexmetalQuotaKey{location: "EU: Rotterdam", instanceType: "bm7.std.8"},
+			want:   onDemand,
+			wantOK: true,
+		},
+		{
+			name:   "commit",
+			key:    flexmetalQuotaKey{location: "EU: Rotterdam", instanceType: "bm7.std.8", contractID: "CONTRACT-123"},
+			want:   commit,
+			wantOK: true,
+		},
+		{
+			name: "other contract",
+			key:  flexmetalQuotaKey{location: "EU: Rotterdam", instanceType: "bm7.std.8", contractID: "CONTRACT-456"},
+		},
+		{
+			name: "other instance type",
+			key:  flexmetalQuotaKey{location: "EU: Rotterdam", instanceType: "bm9.std.16"},
+		},
+		{
+			name: "other location",
+			key:  flexmetalQuotaKey{location: "NA: Montreal", instanceType: "bm7.std.8"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := findFlexmetalQuota(quotas, tt.key)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRemainingQuota(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 4, remainingQuota(one_api.QuotaUsage{Quota: 5, Usage: 1}))
+	require.Equal(t, 0, remainingQuota(one_api.QuotaUsage{Quota: 2, Usage: 2}))
+	// Usage exceeds the quota after the quota was lowered.
+	require.Equal(t, 0, remainingQuota(one_api.QuotaUsage{Quota: 1, Usage: 3}))
+}
+
+func TestCheckFlexmetalServerQuota(t *testing.T) {
+	t.Parallel()
+
+	newCatalog := func() *catalog {
+		return &catalog{
+			flexmetalQuotas: cached[[]one_api.ServerQuotaUsage]{load: func(context.Context) ([]one_api.ServerQuotaUsage, error) {
+				return []one_api.ServerQuotaUsage{{
+					Location: one_api.LocationPartial{ID: 6, Name: "EU: Rotterdam"},
+					QuotaUsage: []one_api.QuotaUsage{
+						{InstanceType: one_api.InstanceTypePartial{ID: 1, Name: "bm7.std.8"}, QuotaType: one_api.QuotaTypeOnDemand, Quota: 3, Usage: 1},
+						{InstanceType: one_api.InstanceTypePartial{ID: 1, Name: "bm7.std.8"}, QuotaType: one_api.QuotaTypeCommit, ContractID: "CONTRACT-123", Quota: 2, Usage: 2},
+					},
+				}}, nil
+			}},
+		}
+	}
+	server := func(name string) map[string]any {
+		return map[string]any{"name": name, "location": "EU: Rotterdam", "instance_type": "bm7.std.8", "quota_check": flexmetalQuotaCheckError}
+	}
+	existing := func(name string) map[string]any {
+		s := server(name)
+		s["uuid"] = "uuid-" + name
+		return s
+	}
+	check := func(c *catalog, plan, state map[string]any) diag.Diagnostics {
+		var diags diag.Diagnostics
+		checkFlexmetalServerQuota(context.Background(), c, modifyPlanRequest(t, NewServerResource(), plan, state), &diags)
+		return diags
+	}
+
+	t.Run("servers planned together", func(t *testing.T) {
+		c := newCatalog()
+		require.False(t, check(c, server("a"), nil).HasError())
+		require.False(t, check(c, server("b"), nil).HasError())
+
+		diags := check(c, server("c"), nil)
+		require.True(t, diags.HasError())
+		require.Contains(t, diags[0].Detail(), "This plan orders at least 3 servers within the on demand quota of bm7.std.8 servers in EU: Rotterdam, which allows 2 more")
+	})
+
+	t.Run("server planned again", func(t *testing.T) {
+		c := newCatalog()
+		for range 3 {
+			require.False(t, check(c, server("a"), nil).HasError())
+		}
+	})
+
+	t.Run("warning", func(t *testing.T) {
+		plan := server("a")
+		plan["contract_id"] = "CONTRACT-123"
+		plan["quota_check"] = flexmetalQuotaCheckWarn
+
+		diags := check(newCatalog(), plan, nil)
+		require.False(t, diags.HasError())
+		require.Equal(t, 1, diags.WarningsCount())
+	})
+
+	t.Run("contract changed in place", func(t *testing.T) {
+		plan := existing("a")
+		plan["contract_id"] = "CONTRACT-123"
+
+		require.Empty(t, check(newCatalog(), plan, existing("a")))
+	})
+
+	t.Run("replaced within the same quota", func(t *testing.T) {
+		c := newCatalog()
+		for _, name := range []string{"a", "b", "c"} {
+			plan := existing(name)
+			plan["post_install_script"] = "echo replaced"
+			require.Empty(t, check(c, plan, existing(name)))
+		}
+	})
+
+	t.Run("replaced into another quota", func(t *testing.T) {
+		plan := existing("a")
+		plan["post_install_script"] = "echo replaced"
+		plan["contract_id"] = "CONTRACT-123"
+
+		diags := check(newCatalog(), plan, existing("a"))
+		require.True(t, diags.HasError())
+		require.Contains(t, diags[0].Detail(), "under contract CONTRACT-123, which allows 0 more")
+	})
+}
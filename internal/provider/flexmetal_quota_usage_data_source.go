@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexmetalQuotaUsageDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexmetalQuotaUsageDataSource)(nil)
+)
+
+func NewFlexmetalQuotaUsageDataSource() datasource.DataSource {
+	return &flexmetalQuotaUsageDataSource{}
+}
+
+// flexmetalQuotaUsageDataSource reads how many FlexMetal servers can be
+// ordered, and how many are ordered, per location and instance type.
+type flexmetalQuotaUsageDataSource struct {
+	client *one_api.Client
+}
+
+type flexmetalQuotaUsageDataSourceModel struct {
+	Location     types.String `tfsdk:"location"`
+	InstanceType types.String `tfsdk:"instance_type"`
+	Quotas       types.List   `tfsdk:"quotas"`
+}
+
+var flexmetalQuotaObjectAttrTypes = map[string]attr.Type{
+	"location":         types.StringType,
+	"location_id":      types.Int64Type,
+	"instance_type":    types.StringType,
+	"instance_type_id": types.Int64Type,
+	"quota_type":       types.StringType,
+	"contract_id":      types.StringType,
+	"quota":            types.Int64Type,
+	"usage":            types.Int64Type,
+	"remaining":        types.Int64Type,
+}
+
+func (d *flexmetalQuotaUsageDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexmetalQuotaUsageDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexmetal_quota_usage"
+}
+
+func (d *flexmetalQuotaUsageDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the FlexMetal server quotas of your i3D.net account and how much of them is used, per " +
+			"location and instance type. This is useful to check how many servers can still be ordered before adding them.",
+		Attributes: map[string]schema.Attribute{
+			"location": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include the quotas of the location with this name, e.g. `EU: Rotterdam`.",
+			},
+			"instance_type": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include the quotas of the instance type with this name, e.g. `bm7.std.8`.",
+			},
+			"quotas": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching quotas.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"location": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the location.",
+						},
+						"location_id": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "ID of the location.",
+						},
+						"instance_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the instance type.",
+						},
+						"instance_type_id": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "ID of the instance type.",
+						},
+						"quota_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The type of quota. Can be \"onDemand\" or \"commit\".",
+						},
+						"contract_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The contract of a `commit` quota. Null for the `onDemand` quota.",
+						},
+						"quota": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of servers that can be ordered.",
+						},
+						"usage": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of servers currently ordered.",
+						},
+						"remaining": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of servers that can still be ordered. Always `quota` minus `usage`, and never negative.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexmetalQuotaUsageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexmetalQuotaUsageDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	quotas, err := d.client.ListQuotaUsage(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error reading FlexMetal quota usage", err, &resp.Diagnostics)
+		return
+	}
+
+	quotaValues := []attr.Value{}
+	for _, locationQuotas := range quotas {
+		if !data.Location.IsNull() && locationQuotas.Location.Name != data.Location.ValueString() {
+			continue
+		}
+		for _, q := range locationQuotas.QuotaUsage {
+			if !data.InstanceType.IsNull() && q.InstanceType.Name != data.InstanceType.ValueString() {
+				continue
+			}
+
+			contractID := types.StringNull()
+			if q.ContractID != "" {
+				contractID = types.StringValue(q.ContractID)
+			}
+
+			obj, diags := types.ObjectValue(flexmetalQuotaObjectAttrTypes, map[string]attr.Value{
+				"location":         types.StringValue(locationQuotas.Location.Name),
+				"location_id":      types.Int64Value(int64(locationQuotas.Location.ID)),
+				"instance_type":    types.StringValue(q.InstanceType.Name),
+				"instance_type_id": types.Int64Value(int64(q.InstanceType.ID)),
+				"quota_type":       types.StringValue(q.QuotaType),
+				"contract_id":      contractID,
+				"quota":            types.Int64Value(int64(q.Quota)),
+				"usage":            types.Int64Value(int64(q.Usage)),
+				"remaining":        types.Int64Value(int64(remainingQuota(q))),
+			})
+			resp.Diagnostics.Append(diags...)
+			quotaValues = append(quotaValues, obj)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	quotasList, diags := types.ListValue(types.ObjectType{AttrTypes: flexmetalQuotaObjectAttrTypes}, quotaValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Quotas = quotasList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// remainingQuota returns the number of servers that can still be ordered
+// within q. Usage can exceed the quota when the quota was lowered.
+func remainingQuota(q one_api.QuotaUsage) int {
+	return max(q.Quota-q.Usage, 0)
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexmetalQuotaUsageDataSource(t *testing.T) {
+	apiclient := newOneAPIClient(t, resourceNsFlexmetal)
+
+	quotas, err := apiclient.ListQuotaUsage(context.Background())
+	require.NoError(t, err)
+
+	nrOfQuotas := 0
+	for _, locationQuotas := range quotas {
+		nrOfQuotas += len(locationQuotas.QuotaUsage)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_quota_usage" "all" {
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Quotas from data source should match quotas from One API
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_quota_usage.all", "quotas.#", strconv.Itoa(nrOfQuotas)),
+				),
+			},
+		},
+	})
+}
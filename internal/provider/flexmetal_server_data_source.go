@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource                     = (*flexmetalServerDataSource)(nil)
+	_ datasource.DataSourceWithConfigure        = (*flexmetalServerDataSource)(nil)
+	_ datasource.DataSourceWithConfigValidators = (*flexmetalServerDataSource)(nil)
+)
+
+func NewFlexmetalServerDataSource() datasource.DataSource {
+	return &flexmetalServerDataSource{}
+}
+
+// flexmetalServerDataSource reads an existing FlexMetal server, so that it
+// can be referenced without importing it into an i3dnet_flexmetal_server
+// resource, which would release it on destroy.
+type flexmetalServerDataSource struct {
+	client *one_api.Client
+}
+
+type flexmetalServerDataSourceModel struct {
+	Uuid          types.String `tfsdk:"uuid"`
+	Name          types.String `tfsdk:"name"`
+	Status        types.String `tfsdk:"status"`
+	StatusMessage types.String `tfsdk:"status_message"`
+	Location      types.String `tfsdk:"location"`
+	InstanceType  types.String `tfsdk:"instance_type"`
+	OsSlug        types.String `tfsdk:"os_slug"`
+	IpAddresses   types.List   `tfsdk:"ip_addresses"`
+	Tags          types.List   `tfsdk:"tags"`
+	CreatedAt     types.Int64  `tfsdk:"created_at"`
+	DeliveredAt   types.Int64  `tfsdk:"delivered_at"`
+	ReleasedAt    types.Int64  `tfsdk:"released_at"`
+	ContractId    types.String `tfsdk:"contract_id"`
+}
+
+func (d *flexmetalServerDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexmetalServerDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexmetal_server"
+}
+
+func (d *flexmetalServerDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := flexmetalServerAttributes()
+	maps.Insert(attributes, maps.All(map[string]schema.Attribute{
+		"uuid": schema.StringAttribute{
+			Optional:            true,
+			Computed:            true,
+			MarkdownDescription: "UUID of the server. Exactly one of `uuid` and `name` must be set.",
+		},
+		"name": schema.StringAttribute{
+			Optional: true,
+			Computed: true,
+			MarkdownDescription: "Host name of the server. Released servers are ignored when looking up a server by name, " +
+				"and the name must match exactly one of the other servers.",
+		},
+		"status_message": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Status message.",
+		},
+		"released_at": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Server release timestamp. Zero while the server is not released.",
+		},
+		"contract_id": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "The contract the server was ordered under. Null when it was not ordered under a contract.",
+		},
+	}))
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get an existing FlexMetal server in your i3D.net account by UUID or name. Unlike importing it " +
+			"into an `i3dnet_flexmetal_server` resource, reading a server with this data source never releases it, which " +
+			"makes it suitable for servers ordered by other teams or by hand.",
+		Attributes: attributes,
+	}
+}
+
+func (d *flexmetalServerDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("uuid"),
+			path.MatchRoot("name"),
+		),
+	}
+}
+
+func (d *flexmetalServerDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexmetalServerDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var server *one_api.Server
+	if !data.Uuid.IsNull() {
+		var err error
+		server, err = d.client.GetServer(ctx, data.Uuid.ValueString())
+		if err != nil {
+			AddErrorResponseToDiags("Error reading FlexMetal server", err, &resp.Diagnostics)
+			return
+		}
+	} else {
+		server = d.findServerByName(ctx, data.Name.ValueString(), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(flexmetalServerRespToModel(ctx, server, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// findServerByName returns the server named name, ignoring released servers.
+// It is an error when no server or several servers have that name.
+func (d *flexmetalServerDataSource) findServerByName(ctx context.Context, name string, diags *diag.Diagnostics) *one_api.Server {
+	servers, err := d.client.ListServers(ctx, "", nil)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexMetal servers", err, diags)
+		return nil
+	}
+
+	servers = slices.DeleteFunc(servers, func(s one_api.Server) bool { return s.Name != name || s.Status == "released" })
+	switch len(servers) {
+	case 0:
+		diags.AddError("FlexMetal server not found", fmt.Sprintf("No FlexMetal server found with name %q", name))
+		return nil
+	case 1:
+		return &servers[0]
+	default:
+		uuids := namesOf(servers, func(s one_api.Server) string { return s.Uuid })
+		diags.AddError("Multiple FlexMetal servers found",
+			fmt.Sprintf("%d FlexMetal servers are named %q: %v. Set uuid instead of name to select one.", len(servers), name, uuids))
+		return nil
+	}
+}
+
+// flexmetalServerRespToModel maps an API server onto the data source model,
+// like serverRespToPlan does for the resource.
+func flexmetalServerRespToModel(ctx context.Context, server *one_api.Server, data *flexmetalServerDataSourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	data.Uuid = types.StringValue(server.Uuid)
+	data.Name = types.StringValue(server.Name)
+	data.Status = types.StringValue(server.Status)
+	data.StatusMessage = types.StringValue(server.StatusMessage)
+	data.Location = types.StringValue(server.Location.Name)
+	data.InstanceType = types.StringValue(server.InstanceType.Name)
+	data.OsSlug = types.StringValue(server.Os.Slug)
+	data.CreatedAt = types.Int64Value(server.CreatedAt)
+	data.DeliveredAt = types.Int64Value(server.DeliveredAt)
+	data.ReleasedAt = types.Int64Value(server.ReleasedAt)
+
+	data.ContractId = types.StringNull()
+	if server.ContractID != "" {
+		data.ContractId = types.StringValue(server.ContractID)
+	}
+
+	addresses := make([]string, 0, len(server.IpAddresses))
+	for _, ip := range server.IpAddresses {
+		addresses = append(addresses, ip.IpAddress)
+	}
+	var d diag.Diagnostics
+	data.IpAddresses, d = types.ListValueFrom(ctx, types.StringType, addresses)
+	diags.Append(d...)
+	data.Tags, d = types.ListValueFrom(ctx, types.StringType, append([]string{}, server.Tags...))
+	diags.Append(d...)
+
+	return diags
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexmetalServerDataSource(t *testing.T) {
+	apiclient := newOneAPIClient(t, resourceNsFlexmetal)
+
+	delivered, err := apiclient.ListServers(context.Background(), "delivered", nil)
+	require.NoError(t, err)
+
+	steps := []resource.TestStep{
+		{
+			Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_server" "test" {
+}
+`,
+			ExpectError: regexp.MustCompile(`No attribute specified when one \(and only one\) of`),
+		},
+		{
+			Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_server" "test" {
+  name = "terraform-acceptance-no-such-server"
+}
+`,
+			ExpectError: regexp.MustCompile(`No FlexMetal server found with name`),
+		},
+	}
+
+	if len(delivered) > 0 {
+		server := delivered[0]
+		steps = append(steps, resource.TestStep{
+			Config: providerConfig(t, resourceNsFlexmetal) + fmt.Sprintf(`
+data "i3dnet_flexmetal_server" "test" {
+  uuid = %q
+}
+`, server.Uuid),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				// Server from data source should match server from One API
+				resource.TestCheckResourceAttr("data.i3dnet_flexmetal_server.test", "name", server.Name),
+				resource.TestCheckResourceAttr("data.i3dnet_flexmetal_server.test", "status", "delivered"),
+				resource.TestCheckResourceAttr("data.i3dnet_flexmetal_server.test", "location", server.Location.Name),
+				resource.TestCheckResourceAttr("data.i3dnet_flexmetal_server.test", "instance_type", server.InstanceType.Name),
+				resource.TestCheckResourceAttr("data.i3dnet_flexmetal_server.test", "ip_addresses.#", fmt.Sprint(len(server.IpAddresses))),
+			),
+		})
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps:                    steps,
+	})
+}
@@ -13,10 +13,12 @@ import (
 	"terraform-provider-i3dnet/internal/provider/modifiers"
 	"terraform-provider-i3dnet/internal/provider/resource_flexmetal_server"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -26,22 +28,28 @@ var waitForReleasedTimeout = 5 * time.Minute
 
 var _ resource.Resource = (*serverResource)(nil)
 var _ resource.ResourceWithConfigure = (*serverResource)(nil)
+var _ resource.ResourceWithValidateConfig = (*serverResource)(nil)
+var _ resource.ResourceWithModifyPlan = (*serverResource)(nil)
 
 func NewServerResource() resource.Resource {
 	return &serverResource{}
 }
 
 type serverResource struct {
-	client *one_api.Client
+	client  *one_api.Client
+	catalog *catalog
 }
 
 type FlexmetalServerModel struct {
 	resource_flexmetal_server.FlexmetalServerModel
-	Timeouts timeouts.Value `tfsdk:"timeouts"`
+	QuotaCheck types.String   `tfsdk:"quota_check"`
+	Timeouts   timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *serverResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+	if data := fromProviderData(req.ProviderData, &resp.Diagnostics); data != nil {
+		r.client, r.catalog = data.client, data.catalog
+	}
 }
 
 func (r *serverResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -86,8 +94,8 @@ func (r *serverResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			"partitions":      partitions,
 			"slug": schema.StringAttribute{
 				Required:            true,
-				Description:         "Identifier of the OS. Available operating systems can be obtained from [/v3/operatingsystem](https://docs.i3d.net/api/api_general#get-v3-operatingsystem). Use the `slug` field from the response.",
-				MarkdownDescription: "Identifier of the OS. Available operating systems can be obtained from [/v3/operatingsystem](https://docs.i3d.net/api/api_general#get-v3-operatingsystem). Use the `slug` field from the response.",
+				Description:         "Identifier of the OS. Available operating systems can be obtained from [/v3/operatingsystem](https://docs.i3d.net/api/api_general#get-v3-operatingsystem) or the `i3dnet_operating_systems` data source. Use the `slug` field from the response.",
+				MarkdownDescription: "Identifier of the OS. Available operating systems can be obtained from [/v3/operatingsystem](https://docs.i3d.net/api/api_general#get-v3-operatingsystem) or the `i3dnet_operating_systems` data source. Use the `slug` field from the response.",
 			},
 		},
 		CustomType:          generatedOSAttribute.CustomType,
@@ -107,14 +115,26 @@ func (r *serverResource) Schema(ctx context.Context, req resource.SchemaRequest,
 	// Add extra info to docs
 	generatedSchema.Attributes["instance_type"] = schema.StringAttribute{
 		Required:            true,
-		Description:         "Server instance type. Available instance types can be obtained from [/v3/flexMetal/location/{locationId}}/instanceTypes](https://docs.i3d.net/api/api_general#get-v3-flexmetal-location-locationid-instancetypes). Use the `name` field from the response.",
-		MarkdownDescription: "Server instance type. Available instance types can be obtained from [/v3/flexMetal/location/{locationId}}/instanceTypes](https://docs.i3d.net/api/api_general#get-v3-flexmetal-location-locationid-instancetypes). Use the `name` field from the response.",
+		Description:         "Server instance type. Available instance types can be obtained from [/v3/flexMetal/location/{locationId}}/instanceTypes](https://docs.i3d.net/api/api_general#get-v3-flexmetal-location-locationid-instancetypes) or the `i3dnet_flexmetal_instance_types` data source. Use the `name` field from the response.",
+		MarkdownDescription: "Server instance type. Available instance types can be obtained from [/v3/flexMetal/location/{locationId}}/instanceTypes](https://docs.i3d.net/api/api_general#get-v3-flexmetal-location-locationid-instancetypes) or the `i3dnet_flexmetal_instance_types` data source. Use the `name` field from the response.",
 	}
 	generatedSchema.Attributes["location"] = schema.StringAttribute{
 		Required:            true,
 		Description:         "Server location. Available locations can be obtained from [/v3/flexMetal/location](https://docs.i3d.net/api/api_general#get-v3-flexmetal-location). Use the `name` field from the response.",
 		MarkdownDescription: "Server location. Available locations can be obtained from [/v3/flexMetal/location](https://docs.i3d.net/api/api_general#get-v3-flexmetal-location). Use the `name` field from the response.",
 	}
+	generatedSchema.Attributes["quota_check"] = schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "Check at plan time that the server fits in the remaining quota of its location and instance " +
+			"type, i.e. the on demand quota or the commit of `contract_id`. Can be `warn` or `error`, to report a server " +
+			"exceeding the quota as a warning or an error. All servers ordered by the plan count towards the quota, " +
+			"including the ones without `quota_check`. Servers with `overflow` under a contract are not checked. " +
+			"Not checked by default.",
+		Validators: []validator.String{
+			stringvalidator.OneOf(flexmetalQuotaCheckWarn, flexmetalQuotaCheckError),
+		},
+	}
+
 	// Add timeouts to schema
 	generatedSchema.Attributes["timeouts"] = timeouts.Attributes(ctx, timeouts.Opts{
 		Create: true,
@@ -122,12 +142,65 @@ func (r *serverResource) Schema(ctx context.Context, req resource.SchemaRequest,
 
 	modifiers.UpdateComputed(generatedSchema, []string{"tags", "overflow", "contract_id"}, false)
 
-	modifiers.ApplyRequireReplace(generatedSchema, []string{"instance_type", "location", "post_install_script"})
+	modifiers.ApplyRequireReplace(generatedSchema, flexmetalServerRequiresReplace)
 	modifiers.ApplyUseStateForUnknown(generatedSchema, []string{"uuid", "status", "status_message", "ip_addresses", "released_at", "created_at", "delivered_at", "overflow"})
 
 	resp.Schema = generatedSchema
 }
 
+// flexmetalServerRequiresReplace are the attributes of a server that cannot
+// be updated in place: changing them orders a new server.
+var flexmetalServerRequiresReplace = []string{"instance_type", "location", "post_install_script"}
+
+// customIpxeSlug is the OS slug that boots the script at os.ipxe_script_url
+// instead of installing an operating system.
+const customIpxeSlug = "custom-ipxe"
+
+// ValidateConfig checks that os.ipxe_script_url is set if, and only if,
+// os.slug is custom-ipxe, as the API requires.
+func (r *serverResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var slug, ipxeScriptUrl types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("os").AtName("slug"), &slug)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("os").AtName("ipxe_script_url"), &ipxeScriptUrl)...)
+	if resp.Diagnostics.HasError() || slug.IsNull() || slug.IsUnknown() || ipxeScriptUrl.IsUnknown() {
+		return
+	}
+
+	if msg, ok := checkIpxeScriptUrl(slug.ValueString(), !ipxeScriptUrl.IsNull()); !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("os").AtName("ipxe_script_url"), "Invalid OS configuration", msg)
+	}
+}
+
+// checkIpxeScriptUrl reports whether an iPXE script URL may be set, or must
+// be set, for slug. If not, it also returns an error message.
+func checkIpxeScriptUrl(slug string, hasIpxeScriptUrl bool) (string, bool) {
+	switch {
+	case slug == customIpxeSlug && !hasIpxeScriptUrl:
+		return fmt.Sprintf("os.ipxe_script_url is required when os.slug is %q.", customIpxeSlug), false
+	case slug != customIpxeSlug && hasIpxeScriptUrl:
+		return fmt.Sprintf("os.ipxe_script_url can only be used when os.slug is %q, got %q.", customIpxeSlug, slug), false
+	}
+	return "", true
+}
+
+// ModifyPlan checks os.slug against the operating systems available for
+// FlexMetal, so that a typo fails the plan rather than a create that can
+// take up to 45 minutes. It then checks the quota, if quota_check is set.
+func (r *serverResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	validatePlannedCatalogName(ctx, req, path.Root("os").AtName("slug"), &r.catalog.flexmetalOSSlugs,
+		"FlexMetal operating system", "Use the i3dnet_operating_systems data source to list the available operating systems.",
+		&resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkFlexmetalServerQuota(ctx, r.catalog, req, &resp.Diagnostics)
+}
+
 func (r *serverResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data FlexmetalServerModel
 
@@ -194,24 +267,13 @@ func (r *serverResource) Create(ctx context.Context, req resource.CreateRequest,
 	ctx, cancel := context.WithTimeout(ctx, createTimeout)
 	defer cancel()
 
-	serverResp, err := r.client.CreateServer(ctx, createServerReq)
+	server, err := r.client.CreateServer(ctx, createServerReq)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating server",
-			fmt.Sprintf("Unexpected error: %v for server name: %s location: %s instance type: %s", err,
-				data.Name.ValueString(),
-				data.Location.ValueString(),
-				data.InstanceType.ValueString(),
-			),
-		)
-		return
-	}
-	if serverResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating server", serverResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error creating server", err, &resp.Diagnostics)
 		return
 	}
 
-	serverRespToPlan(ctx, serverResp.Server, &data)
+	serverRespToPlan(ctx, server, &data)
 
 	// Add resource to TF state earlier to prevent dangling servers
 	// Example: timeout reached, but server is delivered later on
@@ -245,25 +307,21 @@ func (r *serverResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// server is delivered, get its details to save them to state
-	getServerResp, err := r.client.GetServer(ctx, serverID)
+	server, err = r.client.GetServer(ctx, serverID)
 	if err != nil {
-		resp.Diagnostics.AddError("Error getting server", "Unexpected error: "+err.Error())
-		return
-	}
-	if getServerResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error getting server", getServerResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error getting server", err, &resp.Diagnostics)
 		return
 	}
 
-	if len(getServerResp.Server.IpAddresses) == 0 {
+	if len(server.IpAddresses) == 0 {
 		resp.Diagnostics.AddError(
 			"Server creation failed",
-			fmt.Sprintf("Server has no ipAddresses attached.\nServer id: %s", getServerResp.Server.Uuid),
+			fmt.Sprintf("Server has no ipAddresses attached.\nServer id: %s", server.Uuid),
 		)
 		return
 	}
 
-	serverRespToPlan(ctx, getServerResp.Server, &data)
+	serverRespToPlan(ctx, server, &data)
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -322,21 +380,13 @@ func (r *serverResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	serverResp, err := r.client.GetServer(ctx, data.Uuid.ValueString())
+	server, err := r.client.GetServer(ctx, data.Uuid.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading server",
-			"Could not read server by id "+data.Uuid.ValueString()+": "+err.Error(),
-		)
+		AddErrorResponseToDiags("Error reading server", err, &resp.Diagnostics)
 		return
 	}
 
-	if serverResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error reading server", serverResp.ErrorResponse, &resp.Diagnostics)
-		return
-	}
-
-	serverRespToPlan(ctx, serverResp.Server, &data)
+	serverRespToPlan(ctx, server, &data)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -390,26 +440,17 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 			SSHKey:            sskKeys,
 			PostInstallScript: plan.PostInstallScript.ValueString(),
 		}
-		response, err := r.client.ReinstallOs(ctx, plan.Uuid.ValueString(), patchReq)
-
+		server, err := r.client.ReinstallOs(ctx, plan.Uuid.ValueString(), patchReq)
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error updating server OS",
-				fmt.Sprintf("Unexpected error: %v", err),
-			)
-			return
-		}
-
-		if response.ErrorResponse != nil {
-			tflog.Debug(ctx, "Error updating server OS", map[string]interface{}{"err": response.ErrorResponse})
-			AddErrorResponseToDiags("Error updating server OS", response.ErrorResponse, &resp.Diagnostics)
+			tflog.Debug(ctx, "Error updating server OS", map[string]interface{}{"err": err})
+			AddErrorResponseToDiags("Error updating server OS", err, &resp.Diagnostics)
 			return
 		}
 
 		var operationState string
-		tflog.Debug(ctx, fmt.Sprintf("Updating server OS %v", response.Server))
-		err = r.waitForOperationFinish(ctx, response.Server.Uuid, []string{"finished", "failed"}, 20*time.Minute, 15*time.Second, func(c *one_api.Command) {
-			tflog.Debug(ctx, "I am here waiting for OS reinstall operation to finish", map[string]interface{}{"id": response.Server.Uuid, "state": c.State})
+		tflog.Debug(ctx, fmt.Sprintf("Updating server OS %v", server))
+		err = r.waitForOperationFinish(ctx, server.Uuid, []string{"finished", "failed"}, 20*time.Minute, 15*time.Second, func(c *one_api.Command) {
+			tflog.Debug(ctx, "I am here waiting for OS reinstall operation to finish", map[string]interface{}{"id": server.Uuid, "state": c.State})
 			operationState = c.State
 		})
 
@@ -455,50 +496,26 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	for _, tag := range newTags {
-		serverResp, err := r.client.AddTagToServer(ctx, plan.Uuid.ValueString(), tag)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error adding tag to server",
-				"Unexpected error: "+err.Error(),
-			)
-			return
-		}
-		if serverResp.ErrorResponse != nil {
-			AddErrorResponseToDiags("Error adding tag to server", serverResp.ErrorResponse, &resp.Diagnostics)
+		if _, err := r.client.AddTagToServer(ctx, plan.Uuid.ValueString(), tag); err != nil {
+			AddErrorResponseToDiags("Error adding tag to server", err, &resp.Diagnostics)
 			return
 		}
 	}
 
 	for _, tag := range removedTags {
-		serverResp, err := r.client.DeleteTagFromServer(ctx, plan.Uuid.ValueString(), tag)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error deleting tag from server",
-				"Could not delete tag from server, unexpected error: "+err.Error(),
-			)
-			return
-		}
-		if serverResp.ErrorResponse != nil {
-			AddErrorResponseToDiags("Error deleting tag from server", serverResp.ErrorResponse, &resp.Diagnostics)
+		if _, err := r.client.DeleteTagFromServer(ctx, plan.Uuid.ValueString(), tag); err != nil {
+			AddErrorResponseToDiags("Error deleting tag from server", err, &resp.Diagnostics)
 			return
 		}
 	}
 
 	s, err := r.client.GetServer(ctx, plan.Uuid.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting server",
-			"Could not get server, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	if s.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error reading server", s.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error reading server", err, &resp.Diagnostics)
 		return
 	}
 
-	serverRespToPlan(ctx, s.Server, &plan)
+	serverRespToPlan(ctx, s, &plan)
 
 	// Save updated plan into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
@@ -519,22 +536,13 @@ func (r *serverResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	serverResp, err := r.client.DeleteServer(ctx, data.Uuid.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting server",
-			"Could not delete server by id: "+err.Error(),
-		)
-		return
-	}
-
-	if serverResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error deleting server", serverResp.ErrorResponse, &resp.Diagnostics)
+	if _, err := r.client.DeleteServer(ctx, data.Uuid.ValueString()); err != nil {
+		AddErrorResponseToDiags("Error deleting server", err, &resp.Diagnostics)
 		return
 	}
 
 	lastStatus := data.Status.ValueString()
-	err = r.waitForStatus(ctx, data.Uuid.ValueString(), []string{"released"}, waitForReleasedTimeout, 1*time.Second, func(s *one_api.Server) {
+	err := r.waitForStatus(ctx, data.Uuid.ValueString(), []string{"released"}, waitForReleasedTimeout, 1*time.Second, func(s *one_api.Server) {
 		lastStatus = s.Status
 	})
 	if err != nil {
@@ -557,23 +565,18 @@ func (r *serverResource) waitForStatus(ctx context.Context, serverID string, des
 		case <-deadline:
 			return fmt.Errorf("timeout reached while waiting for server status")
 		case <-ticker.C:
-			serverResponse, err := r.client.GetServer(ctx, serverID)
+			server, err := r.client.GetServer(ctx, serverID)
 			if err != nil {
-				tflog.Error(ctx, "error getting server by id", map[string]interface{}{"id": serverID})
-				continue
-			}
-
-			if serverResponse.ErrorResponse != nil {
-				tflog.Error(ctx, "error response on get server", map[string]interface{}{"errorMsg": serverResponse.ErrorResponse.ErrorMessage})
+				tflog.Error(ctx, "error getting server by id", map[string]interface{}{"id": serverID, "err": err})
 				continue
 			}
 
-			if serverResponse.Server != nil && onServerResponse != nil {
-				onServerResponse(serverResponse.Server)
+			if onServerResponse != nil {
+				onServerResponse(server)
 			}
 
-			if slices.Contains(desiredStatuses, serverResponse.Server.Status) {
-				tflog.Info(ctx, fmt.Sprintf("server reached desired status: %s", serverResponse.Server.Status))
+			if slices.Contains(desiredStatuses, server.Status) {
+				tflog.Info(ctx, fmt.Sprintf("server reached desired status: %s", server.Status))
 				return nil
 			}
 		}
@@ -594,23 +597,18 @@ func (r *serverResource) waitForOperationFinish(ctx context.Context, serverID st
 		case <-deadline:
 			return fmt.Errorf("timeout reached while waiting for operation status")
 		case <-ticker.C:
-			operationStatus, err := r.client.GetOperationStatus(ctx, serverID)
+			command, err := r.client.GetOperationStatus(ctx, serverID)
 			if err != nil {
 				tflog.Error(ctx, "error getting operation state", map[string]interface{}{"err": err})
 				continue
 			}
 
-			if operationStatus.ErrorResponse != nil {
-				tflog.Error(ctx, "error response on get server", map[string]interface{}{"errorMsg": operationStatus.ErrorResponse.ErrorMessage})
-				continue
-			}
-
-			if operationStatus.Command != nil && onServerResponse != nil {
-				onServerResponse(operationStatus.Command)
+			if onServerResponse != nil {
+				onServerResponse(command)
 			}
 
-			if slices.Contains(desiredStatuses, operationStatus.Command.State) {
-				tflog.Info(ctx, fmt.Sprintf("server reached desired status: %s", operationStatus.Command.State))
+			if slices.Contains(desiredStatuses, command.State) {
+				tflog.Info(ctx, fmt.Sprintf("server reached desired status: %s", command.State))
 				return nil
 			}
 		}
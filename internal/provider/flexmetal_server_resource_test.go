@@ -1,16 +1,18 @@
 package provider
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAccFlexmetalServerResourceWithUpdate(t *testing.T) {
 	t.Parallel()
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -101,3 +103,58 @@ resource "i3dnet_flexmetal_server" "my-custom-ipxe" {
 		},
 	})
 }
+
+func TestAccFlexmetalServerResourceInvalidOS(t *testing.T) {
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+resource "i3dnet_flexmetal_server" "invalid-slug" {
+  name          = "invalidSlugHostNameAcceptanceTest"
+  location      = "EU: Rotterdam"
+  instance_type = "bm7.std.8"
+  os = {
+    slug = "ubuntu-2404-lt"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Did\s+you\s+mean\s+"ubuntu-2404-lts"\?`),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+resource "i3dnet_flexmetal_server" "missing-ipxe-script-url" {
+  name          = "customIpxeHostNameAcceptanceTest"
+  location      = "EU: Rotterdam"
+  instance_type = "bm7.std.8"
+  os = {
+    slug = "custom-ipxe"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`os.ipxe_script_url\s+is\s+required`),
+			},
+		},
+	})
+}
+
+func TestCheckIpxeScriptUrl(t *testing.T) {
+	t.Parallel()
+
+	_, ok := checkIpxeScriptUrl("custom-ipxe", true)
+	require.True(t, ok)
+	_, ok = checkIpxeScriptUrl("ubuntu-2404-lts", false)
+	require.True(t, ok)
+
+	msg, ok := checkIpxeScriptUrl("custom-ipxe", false)
+	require.False(t, ok)
+	require.Equal(t, `os.ipxe_script_url is required when os.slug is "custom-ipxe".`, msg)
+
+	msg, ok = checkIpxeScriptUrl("ubuntu-2404-lts", true)
+	require.False(t, ok)
+	require.Equal(t, `os.ipxe_script_url can only be used when os.slug is "custom-ipxe", got "ubuntu-2404-lts".`, msg)
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ ephemeral.EphemeralResource = (*serverRootPasswordEphemeralResource)(nil)
+var _ ephemeral.EphemeralResourceWithConfigure = (*serverRootPasswordEphemeralResource)(nil)
+
+func NewServerRootPasswordEphemeralResource() ephemeral.EphemeralResource {
+	return &serverRootPasswordEphemeralResource{}
+}
+
+// serverRootPasswordEphemeralResource fetches the generated root password of a
+// FlexMetal server. Being ephemeral, the password is never stored in the plan
+// or state.
+type serverRootPasswordEphemeralResource struct {
+	client *one_api.Client
+}
+
+type serverRootPasswordEphemeralResourceModel struct {
+	Uuid         types.String `tfsdk:"uuid"`
+	RootPassword types.String `tfsdk:"root_password"`
+}
+
+func (r *serverRootPasswordEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *serverRootPasswordEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexmetal_server_root_password"
+}
+
+func (r *serverRootPasswordEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Get the root password generated for a FlexMetal server. The password is only available for Windows " +
+			"servers, within the first 24 hours after installation.\n\nAs an ephemeral resource, the password is never " +
+			"stored in the plan or state. Requires Terraform 1.10 or later.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Required:            true,
+				Description:         "UUID of the FlexMetal server.",
+				MarkdownDescription: "UUID of the FlexMetal server.",
+			},
+			"root_password": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				Description:         "Root password of the server.",
+				MarkdownDescription: "Root password of the server.",
+			},
+		},
+	}
+}
+
+func (r *serverRootPasswordEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data serverRootPasswordEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	password, err := r.client.GetServerRootPassword(ctx, data.Uuid.ValueString())
+	if one_api.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Root password not available",
+			fmt.Sprintf("No root password found for FlexMetal server %s. The root password is only available for "+
+				"Windows servers, within the first 24 hours after installation.", data.Uuid.ValueString()),
+		)
+		return
+	}
+	if err != nil {
+		AddErrorResponseToDiags("Unable to Read i3D.net FlexMetal server root password", err, &resp.Diagnostics)
+		return
+	}
+
+	data.RootPassword = types.StringValue(password.RootPassword)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexmetalServerRootPasswordEphemeralResource(t *testing.T) {
+	t.Parallel()
+
+	serverUUID := windowsServerUUID(t)
+
+	factories := testAccProtoV6ProviderFactories(t)
+	factories["echo"] = echoprovider.NewProviderServer()
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: factories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + fmt.Sprintf(`
+ephemeral "i3dnet_flexmetal_server_root_password" "test" {
+  uuid = "%s"
+}
+
+provider "echo" {
+  data = ephemeral.i3dnet_flexmetal_server_root_password.test
+}
+
+resource "echo" "test" {}
+`, serverUUID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("echo.test", "data.uuid", serverUUID),
+					resource.TestCheckResourceAttrSet("echo.test", "data.root_password"),
+				),
+			},
+		},
+	})
+}
+
+// windowsServerUUID returns the UUID of a Windows FlexMetal server installed
+// less than 24 hours ago, which has a root password. Against a real
+// environment it is read from I3D_FLEXMETAL_WINDOWS_SERVER_UUID; against the
+// fake API, a server is created.
+func windowsServerUUID(t *testing.T) string {
+	t.Helper()
+
+	if !useFakeAPI() {
+		serverUUID := os.Getenv("I3D_FLEXMETAL_WINDOWS_SERVER_UUID")
+		if serverUUID == "" {
+			t.Skip("To run this test, set I3D_FLEXMETAL_WINDOWS_SERVER_UUID env var")
+		}
+		return serverUUID
+	}
+
+	server, err := newOneAPIClient(t, resourceNsFlexmetal).CreateServer(context.Background(), one_api.CreateServerReq{
+		Name:         "windowsAcceptanceTest",
+		Location:     "EU: Rotterdam",
+		InstanceType: "bm7.std.8",
+		OS:           one_api.OS{Slug: "windows-2022"},
+	})
+	require.NoError(t, err)
+
+	return server.Uuid
+}
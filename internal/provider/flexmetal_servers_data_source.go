@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexmetalServersDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexmetalServersDataSource)(nil)
+)
+
+func NewFlexmetalServersDataSource() datasource.DataSource {
+	return &flexmetalServersDataSource{}
+}
+
+// flexmetalServersDataSource lists the FlexMetal servers of the account,
+// including servers that are not managed by Terraform.
+type flexmetalServersDataSource struct {
+	client *one_api.Client
+}
+
+type flexmetalServersDataSourceModel struct {
+	Status  types.String `tfsdk:"status"`
+	Tags    types.List   `tfsdk:"tags"`
+	Servers types.List   `tfsdk:"servers"`
+}
+
+var flexmetalServerObjectAttrTypes = map[string]attr.Type{
+	"uuid":          types.StringType,
+	"name":          types.StringType,
+	"status":        types.StringType,
+	"location":      types.StringType,
+	"instance_type": types.StringType,
+	"os_slug":       types.StringType,
+	"ip_addresses":  types.ListType{ElemType: types.StringType},
+	"tags":          types.ListType{ElemType: types.StringType},
+	"created_at":    types.Int64Type,
+	"delivered_at":  types.Int64Type,
+}
+
+func (d *flexmetalServersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexmetalServersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexmetal_servers"
+}
+
+func (d *flexmetalServersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the FlexMetal servers in your i3D.net account, optionally filtered by status and tags, " +
+			"including servers that are not managed by this Terraform configuration. This is useful to target servers " +
+			"in inventory or monitoring configurations.",
+		Attributes: map[string]schema.Attribute{
+			"status": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include servers with this status, e.g. `delivered`.",
+			},
+			"tags": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Only include servers that have all of these tags.",
+			},
+			"servers": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching servers.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: flexmetalServerAttributes(),
+				},
+			},
+		},
+	}
+}
+
+// flexmetalServerAttributes returns the attributes describing a server, as
+// set by flexmetalServerValues.
+func flexmetalServerAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"uuid": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Server UUID.",
+		},
+		"name": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Server host name.",
+		},
+		"status": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Server delivery status.",
+		},
+		"location": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Name of the location of the server.",
+		},
+		"instance_type": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Name of the instance type of the server.",
+		},
+		"os_slug": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Slug of the operating system installed on the server.",
+		},
+		"ip_addresses": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Computed:            true,
+			MarkdownDescription: "The IP addresses of the server.",
+		},
+		"tags": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Computed:            true,
+			MarkdownDescription: "Server tags.",
+		},
+		"created_at": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Server creation timestamp.",
+		},
+		"delivered_at": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Server delivery timestamp.",
+		},
+	}
+}
+
+func (d *flexmetalServersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexmetalServersDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var tags []string
+	resp.Diagnostics.Append(data.Tags.ElementsAs(ctx, &tags, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	servers, err := d.client.ListServers(ctx, data.Status.ValueString(), tags)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexMetal servers", err, &resp.Diagnostics)
+		return
+	}
+
+	serverValues := make([]attr.Value, 0, len(servers))
+	for _, server := range servers {
+		values, diags := flexmetalServerValues(ctx, server)
+		resp.Diagnostics.Append(diags...)
+
+		obj, diags := types.ObjectValue(flexmetalServerObjectAttrTypes, values)
+		resp.Diagnostics.Append(diags...)
+		serverValues = append(serverValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serversList, diags := types.ListValue(types.ObjectType{AttrTypes: flexmetalServerObjectAttrTypes}, serverValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Servers = serversList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// flexmetalServerValues returns the values of the attributes of
+// flexmetalServerAttributes for server.
+func flexmetalServerValues(ctx context.Context, server one_api.Server) (map[string]attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	addresses := make([]string, 0, len(server.IpAddresses))
+	for _, ip := range server.IpAddresses {
+		addresses = append(addresses, ip.IpAddress)
+	}
+	ipAddresses, d := types.ListValueFrom(ctx, types.StringType, addresses)
+	diags.Append(d...)
+
+	tags, d := types.ListValueFrom(ctx, types.StringType, append([]string{}, server.Tags...))
+	diags.Append(d...)
+
+	return map[string]attr.Value{
+		"uuid":          types.StringValue(server.Uuid),
+		"name":          types.StringValue(server.Name),
+		"status":        types.StringValue(server.Status),
+		"location":      types.StringValue(server.Location.Name),
+		"instance_type": types.StringValue(server.InstanceType.Name),
+		"os_slug":       types.StringValue(server.Os.Slug),
+		"ip_addresses":  ipAddresses,
+		"tags":          tags,
+		"created_at":    types.Int64Value(server.CreatedAt),
+		"delivered_at":  types.Int64Value(server.DeliveredAt),
+	}, diags
+}
@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexmetalServersDataSource(t *testing.T) {
+	apiclient := newOneAPIClient(t, resourceNsFlexmetal)
+
+	delivered, err := apiclient.ListServers(context.Background(), "delivered", nil)
+	require.NoError(t, err)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_flexmetal_servers" "delivered" {
+  status = "delivered"
+}
+
+data "i3dnet_flexmetal_servers" "none" {
+  tags = ["terraform-acceptance-no-such-tag"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Servers from data source should match servers from One API
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_servers.delivered", "servers.#", strconv.Itoa(len(delivered))),
+					resource.TestCheckResourceAttr("data.i3dnet_flexmetal_servers.none", "servers.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// flexvmCapacityKey identifies the VMs planned in a Cloud with an instance
+// type, which are checked against the capacity of the Cloud together.
+type flexvmCapacityKey struct {
+	cloudID      string
+	instanceType string
+}
+
+// checkFlexvmVMCapacity warns when the Cloud of a planned VM has no room left
+// for it. Such a VM is not placed on any node, so creating it fails late or
+// times out.
+//
+// The VMs of an instance type planned in the same Cloud are counted together,
+// against the capacity fetched once per run, so only the VMs that no longer
+// fit are reported. This is a warning rather than an error, as nodes created
+// by the same apply do not count yet, and VMs of other instance types planned
+// in the Cloud are not taken into account.
+func checkFlexvmVMCapacity(ctx context.Context, c *catalog, req resource.ModifyPlanRequest, diags *diag.Diagnostics) {
+	var plan, state FlexvmVMModel
+	diags.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		diags.Append(req.State.Get(ctx, &state)...)
+	}
+	if diags.HasError() {
+		return
+	}
+
+	if plan.CloudID.IsUnknown() || plan.InstanceTypeName.IsUnknown() {
+		return
+	}
+	if plan.CloudID.Equal(state.CloudID) && plan.InstanceTypeName.Equal(state.InstanceTypeName) {
+		// No new VM is created, or a replaced VM is deleted before its
+		// replacement is created, freeing its resources.
+		return
+	}
+
+	cloudID := plan.CloudID.ValueString()
+	key := flexvmCapacityKey{cloudID: cloudID, instanceType: plan.InstanceTypeName.ValueString()}
+	planned := c.plannedFlexvmVMs.add(key, plannedResourceID(req, state.ID, plan.Name))
+
+	// Failing to load the instance types was already reported when validating
+	// instance_type_name.
+	instanceTypes, err := c.flexvmInstanceTypeSpecs.get(ctx)
+	if err != nil {
+		return
+	}
+	instanceType, ok := findFlexvmInstanceType(instanceTypes, key.instanceType)
+	if !ok {
+		return
+	}
+
+	capacities, err := c.flexvmCloudCapacities.get(ctx)
+	if err != nil {
+		diags.AddWarning("Unable to check FlexVM Cloud capacity",
+			fmt.Sprintf("The capacity of Cloud %s could not be fetched, so it is not checked before applying.\nError: %v",
+				cloudID, err),
+		)
+		return
+	}
+	i := slices.IndexFunc(capacities, func(c one_api.FlexvmCloudCapacity) bool { return c.ID == cloudID })
+	if i < 0 {
+		// Creating the VM reports the unknown Cloud.
+		return
+	}
+	available := capacities[i].Resources
+
+	// A VM moved to another instance type in the same Cloud is deleted
+	// before its replacement is created, freeing its resources.
+	if plan.CloudID.Equal(state.CloudID) {
+		if replaced, ok := findFlexvmInstanceType(instanceTypes, state.InstanceTypeName.ValueString()); ok {
+			available = releaseFlexvmVM(available, replaced)
+		}
+	}
+
+	fits := flexvmVMsThatFit(available, instanceType)
+	tflog.Debug(ctx, "Checked FlexVM Cloud capacity", map[string]any{
+		"cloud_id": cloudID, "instance_type": instanceType.Name, "vms_that_fit": fits, "planned": planned,
+	})
+	if planned <= fits {
+		return
+	}
+
+	diags.AddAttributeWarning(path.Root("instance_type_name"), "Insufficient FlexVM Cloud capacity",
+		fmt.Sprintf("Cloud %s has room for %d more VMs of instance type %q, but this plan creates at least %d. "+
+			"Each VM requires %s, and the Cloud has %s available.\n"+
+			"The VMs that do not fit will not be placed until capacity is freed, so creating them will likely time out. "+
+			"Add an i3dnet_flexvm_node to the Cloud to increase its capacity.",
+			cloudID, fits, instanceType.Name, planned,
+			describeFlexvmResources(instanceType.VCPU, instanceType.Memory, flexvmPCIDeviceCounts(instanceType)),
+			describeFlexvmResources(available.VCPU.Available, available.Memory.Available, availablePCIDevices(available, instanceType))),
+	)
+}
+
+func findFlexvmInstanceType(instanceTypes []one_api.FlexvmInstanceType, name string) (one_api.FlexvmInstanceType, bool) {
+	i := slices.IndexFunc(instanceTypes, func(it one_api.FlexvmInstanceType) bool { return it.Name == name })
+	if i < 0 {
+		return one_api.FlexvmInstanceType{}, false
+	}
+	return instanceTypes[i], true
+}
+
+// flexvmVMsThatFit returns how many VMs of instance type it fit in the
+// available resources.
+func flexvmVMsThatFit(available one_api.FlexvmCapacityResources, it one_api.FlexvmInstanceType) int {
+	fits := math.MaxInt
+	if it.VCPU > 0 {
+		fits = min(fits, available.VCPU.Available/it.VCPU)
+	}
+	if it.Memory > 0 {
+		fits = min(fits, available.Memory.Available/it.Memory)
+	}
+	for deviceType, count := range flexvmPCIDeviceCounts(it) {
+		fits = min(fits, available.PCIDevice(deviceType).Available/count)
+	}
+	return max(fits, 0)
+}
+
+// releaseFlexvmVM returns available with the resources of a VM of instance
+// type it made available again.
+func releaseFlexvmVM(available one_api.FlexvmCapacityResources, it one_api.FlexvmInstanceType) one_api.FlexvmCapacityResources {
+	available.VCPU.Available += it.VCPU
+	available.Memory.Available += it.Memory
+
+	available.PCIDevices = slices.Clone(available.PCIDevices)
+	for deviceType, count := range flexvmPCIDeviceCounts(it) {
+		i := slices.IndexFunc(available.PCIDevices, func(d one_api.FlexvmCapacityPCIDevice) bool { return d.Type == deviceType })
+		if i < 0 {
+			available.PCIDevices = append(available.PCIDevices, one_api.FlexvmCapacityPCIDevice{Type: deviceType})
+			i = len(available.PCIDevices) - 1
+		}
+		available.PCIDevices[i].Available += count
+	}
+
+	return available
+}
+
+// flexvmPCIDeviceCounts returns the number of PCI passthrough devices a VM of
+// instance type it requires, by type of device.
+func flexvmPCIDeviceCounts(it one_api.FlexvmInstanceType) map[string]int {
+	counts := map[string]int{}
+	for _, d := range it.PCIDevices {
+		counts[d.Type]++
+	}
+	return counts
+}
+
+// availablePCIDevices returns the number of available PCI passthrough devices
+// of the types instance type it requires.
+func availablePCIDevices(available one_api.FlexvmCapacityResources, it one_api.FlexvmInstanceType) map[string]int {
+	counts := map[string]int{}
+	for deviceType := range flexvmPCIDeviceCounts(it) {
+		counts[deviceType] = available.PCIDevice(deviceType).Available
+	}
+	return counts
+}
+
+// describeFlexvmResources describes resources for diagnostics, e.g.
+// "8 vCPU, 16384 MB of memory and 1 gpu".
+func describeFlexvmResources(vcpu, memory int, pciDevices map[string]int) string {
+	parts := []string{fmt.Sprintf("%d vCPU", vcpu), fmt.Sprintf("%d MB of memory", memory)}
+	for _, deviceType := range slices.Sorted(maps.Keys(pciDevices)) {
+		parts = append(parts, fmt.Sprintf("%d %s", pciDevices[deviceType], deviceType))
+	}
+
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexvmCapacityDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexvmCapacityDataSource)(nil)
+)
+
+func NewFlexvmCapacityDataSource() datasource.DataSource {
+	return &flexvmCapacityDataSource{}
+}
+
+// flexvmCapacityDataSource reads the capacity of a FlexVM Cloud, or of the
+// whole organization.
+type flexvmCapacityDataSource struct {
+	client *one_api.Client
+}
+
+type flexvmCapacityDataSourceModel struct {
+	CloudID    types.String `tfsdk:"cloud_id"`
+	Nodes      types.Int64  `tfsdk:"nodes"`
+	NodeGroups types.List   `tfsdk:"node_groups"`
+	VCPU       types.Object `tfsdk:"vcpu"`
+	Memory     types.Object `tfsdk:"memory"`
+	PCIDevices types.List   `tfsdk:"pci_devices"`
+}
+
+var flexvmCapacityNodeGroupObjectAttrTypes = map[string]attr.Type{
+	"instance_type": types.StringType,
+	"quantity":      types.Int64Type,
+}
+
+var flexvmCapacityResourceObjectAttrTypes = map[string]attr.Type{
+	"total":     types.Int64Type,
+	"in_use":    types.Int64Type,
+	"available": types.Int64Type,
+}
+
+var flexvmCapacityPCIDeviceObjectAttrTypes = map[string]attr.Type{
+	"type":      types.StringType,
+	"total":     types.Int64Type,
+	"in_use":    types.Int64Type,
+	"available": types.Int64Type,
+}
+
+func (d *flexvmCapacityDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexvmCapacityDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_capacity"
+}
+
+func (d *flexvmCapacityDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resourceAttributes := func(unit string) map[string]schema.Attribute {
+		return map[string]schema.Attribute{
+			"total": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: fmt.Sprintf("Total %s. Always `in_use` plus `available`.", unit),
+			},
+			"in_use": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: fmt.Sprintf("%s currently in use.", unit),
+			},
+			"available": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: fmt.Sprintf("%s still available for new VMs.", unit),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get a capacity snapshot of an i3D.net FlexVM Cloud, or of all Clouds of your organization. " +
+			"This is useful to check whether a Cloud has room for more VMs before adding them.",
+		Attributes: map[string]schema.Attribute{
+			"cloud_id": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "UUID of the Cloud to get the capacity of. When not set, the capacity of all Clouds " +
+					"of the organization is returned.",
+			},
+			"nodes": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Number of nodes in scope.",
+			},
+			"node_groups": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Nodes grouped by FlexMetal instance type.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"instance_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The FlexMetal instance type of the nodes.",
+						},
+						"quantity": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of nodes of the instance type.",
+						},
+					},
+				},
+			},
+			"vcpu": schema.SingleNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Virtual CPU utilization.",
+				Attributes:          resourceAttributes("vCPU cores"),
+			},
+			"memory": schema.SingleNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Memory utilization.",
+				Attributes:          resourceAttributes("MB of memory"),
+			},
+			"pci_devices": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "PCI passthrough device utilization, by type of device.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Device type. Can be \"gpu\" or \"nvme\".",
+						},
+						"total": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Total number of devices. Always `in_use` plus `available`.",
+						},
+						"in_use": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of devices currently in use.",
+						},
+						"available": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of devices still available for new VMs.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexvmCapacityDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexvmCapacityDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var capacity *one_api.FlexvmCapacity
+	if data.CloudID.IsNull() {
+		var err error
+		capacity, err = d.client.FlexvmGetCapacity(ctx)
+		if err != nil {
+			AddErrorResponseToDiags("Error reading FlexVM capacity", err, &resp.Diagnostics)
+			return
+		}
+	} else {
+		capacity = cloudCapacity(ctx, d.client, data.CloudID.ValueString(), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(flexvmCapacityToState(capacity, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// cloudCapacity returns the capacity of the Cloud cloudID. The API has no
+// endpoint for a single Cloud, so the capacity of every Cloud is listed.
+func cloudCapacity(ctx context.Context, client *one_api.Client, cloudID string, diags *diag.Diagnostics) *one_api.FlexvmCapacity {
+	capacities, err := client.FlexvmListCloudCapacities(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error reading FlexVM capacity", err, diags)
+		return nil
+	}
+
+	for _, c := range capacities {
+		if c.ID == cloudID {
+			return &c.FlexvmCapacity
+		}
+	}
+
+	diags.AddError("FlexVM Cloud not found", fmt.Sprintf("No FlexVM Cloud found for id %s", cloudID))
+	return nil
+}
+
+func flexvmCapacityToState(capacity *one_api.FlexvmCapacity, data *flexvmCapacityDataSourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	groupValues := make([]attr.Value, 0, len(capacity.NodeGroups))
+	for _, g := range capacity.NodeGroups {
+		obj, d := types.ObjectValue(flexvmCapacityNodeGroupObjectAttrTypes, map[string]attr.Value{
+			"instance_type": types.StringValue(g.InstanceType),
+			"quantity":      types.Int64Value(int64(g.Quantity)),
+		})
+		diags.Append(d...)
+		groupValues = append(groupValues, obj)
+	}
+
+	deviceValues := make([]attr.Value, 0, len(capacity.Resources.PCIDevices))
+	for _, device := range capacity.Resources.PCIDevices {
+		obj, d := types.ObjectValue(flexvmCapacityPCIDeviceObjectAttrTypes, map[string]attr.Value{
+			"type":      types.StringValue(device.Type),
+			"total":     types.Int64Value(int64(device.Total)),
+			"in_use":    types.Int64Value(int64(device.InUse)),
+			"available": types.Int64Value(int64(device.Available)),
+		})
+		diags.Append(d...)
+		deviceValues = append(deviceValues, obj)
+	}
+
+	resourceValue := func(r one_api.FlexvmCapacityResource) types.Object {
+		obj, d := types.ObjectValue(flexvmCapacityResourceObjectAttrTypes, map[string]attr.Value{
+			"total":     types.Int64Value(int64(r.Total)),
+			"in_use":    types.Int64Value(int64(r.InUse)),
+			"available": types.Int64Value(int64(r.Available)),
+		})
+		diags.Append(d...)
+		return obj
+	}
+
+	var d diag.Diagnostics
+	data.Nodes = types.Int64Value(int64(capacity.Nodes))
+	data.NodeGroups, d = types.ListValue(types.ObjectType{AttrTypes: flexvmCapacityNodeGroupObjectAttrTypes}, groupValues)
+	diags.Append(d...)
+	data.VCPU = resourceValue(capacity.Resources.VCPU)
+	data.Memory = resourceValue(capacity.Resources.Memory)
+	data.PCIDevices, d = types.ListValue(types.ObjectType{AttrTypes: flexvmCapacityPCIDeviceObjectAttrTypes}, deviceValues)
+	diags.Append(d...)
+
+	return diags
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFlexvmCapacityDataSource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_capacity" "cloud" {
+  cloud_id = "019d24e2-98fa-701a-8475-8ac0ff1f4a4a"
+}
+
+data "i3dnet_flexvm_capacity" "organization" {}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_capacity.cloud", "nodes"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_capacity.cloud", "vcpu.total"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_capacity.cloud", "vcpu.available"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_capacity.cloud", "memory.total"),
+					resource.TestCheckNoResourceAttr("data.i3dnet_flexvm_capacity.organization", "cloud_id"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_capacity.organization", "nodes"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_capacity.organization", "memory.in_use"),
+				),
+			},
+		},
+	})
+}
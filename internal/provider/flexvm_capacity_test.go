@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlexvmVMsThatFit(t *testing.T) {
+	t.Parallel()
+
+	small := one_api.FlexvmInstanceType{Name: "vm.4c.8g", VCPU: 4, Memory: 8192}
+	gpu := one_api.FlexvmInstanceType{
+		Name: "vm.gpu.8c.32g", VCPU: 8, Memory: 32768,
+		PCIDevices: []one_api.FlexvmPCIDevice{{Type: "gpu", Spec: "rtx4000"}},
+	}
+
+	resources := func(vcpu, memory, gpus int) one_api.FlexvmCapacityResources {
+		return one_api.FlexvmCapacityResources{
+			VCPU:   one_api.FlexvmCapacityResource{Available: vcpu},
+			Memory: one_api.FlexvmCapacityResource{Available: memory},
+			PCIDevices: []one_api.FlexvmCapacityPCIDevice{
+				{Type: "gpu", FlexvmCapacityResource: one_api.FlexvmCapacityResource{Available: gpus}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		available    one_api.FlexvmCapacityResources
+		instanceType one_api.FlexvmInstanceType
+		want         int
+	}{
+		{
+			name:         "limited by vcpu",
+			available:    resources(10, 65536, 0),
+			instanceType: small,
+			want:         2,
+		},
+		{
+			name:         "limited by memory",
+			available:    resources(64, 20000, 0),
+			instanceType: small,
+			want:         2,
+		},
+		{
+			name:         "limited by gpus",
+			available:    resources(64, 262144, 1),
+			instanceType: gpu,
+			want:         1,
+		},
+		{
+			name:         "no gpu left",
+			available:    resources(64, 262144, 0),
+			instanceType: gpu,
+			want:         0,
+		},
+		{
+			name:         "no nodes",
+			available:    one_api.FlexvmCapacityResources{},
+			instanceType: small,
+			want:         0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, flexvmVMsThatFit(tt.available, tt.instanceType))
+		})
+	}
+}
+
+func TestReleaseFlexvmVM(t *testing.T) {
+	t.Parallel()
+
+	available := one_api.FlexvmCapacityResources{
+		VCPU:   one_api.FlexvmCapacityResource{Total: 64, InUse: 64},
+		Memory: one_api.FlexvmCapacityResource{Total: 524288, InUse: 524288},
+	}
+	gpu := one_api.FlexvmInstanceType{
+		Name: "vm.gpu.8c.32g", VCPU: 8, Memory: 32768,
+		PCIDevices: []one_api.FlexvmPCIDevice{{Type: "gpu"}},
+	}
+
+	released := releaseFlexvmVM(available, gpu)
+	require.Equal(t, 8, released.VCPU.Available)
+	require.Equal(t, 32768, released.Memory.Available)
+	require.Equal(t, 1, released.PCIDevice("gpu").Available)
+	require.Equal(t, 1, flexvmVMsThatFit(released, gpu))
+
+	// available is left untouched.
+	require.Zero(t, available.VCPU.Available)
+	require.Empty(t, available.PCIDevices)
+}
+
+func TestDescribeFlexvmResources(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "4 vCPU and 8192 MB of memory", describeFlexvmResources(4, 8192, nil))
+	require.Equal(t, "8 vCPU, 32768 MB of memory, 1 gpu and 2 nvme",
+		describeFlexvmResources(8, 32768, map[string]int{"nvme": 2, "gpu": 1}))
+}
+
+func TestCheckFlexvmVMCapacity(t *testing.T) {
+	t.Parallel()
+
+	small := one_api.FlexvmInstanceType{Name: "vm.4c.8g", VCPU: 4, Memory: 8192}
+	newCatalog := func() *catalog {
+		return &catalog{
+			flexvmInstanceTypeSpecs: cached[[]one_api.FlexvmInstanceType]{load: func(context.Context) ([]one_api.FlexvmInstanceType, error) {
+				return []one_api.FlexvmInstanceType{small}, nil
+			}},
+			flexvmCloudCapacities: cached[[]one_api.FlexvmCloudCapacity]{load: func(context.Context) ([]one_api.FlexvmCloudCapacity, error) {
+				capacity := one_api.FlexvmCloudCapacity{}
+				capacity.ID = "cloud"
+				// Room for 2 VMs.
+				capacity.Resources.VCPU.Available = 8
+				capacity.Resources.Memory.Available = 65536
+				return []one_api.FlexvmCloudCapacity{capacity}, nil
+			}},
+		}
+	}
+	vm := func(name string) map[string]any {
+		return map[string]any{"cloud_id": "cloud", "name": name, "instance_type_name": small.Name}
+	}
+
+	t.Run("VMs planned together", func(t *testing.T) {
+		c := newCatalog()
+		var warnings []int
+		for i := range 5 {
+			var diags diag.Diagnostics
+			checkFlexvmVMCapacity(context.Background(), c, modifyPlanRequest(t, NewFlexvmVMResource(), vm(fmt.Sprintf("vm-%d", i)), nil), &diags)
+			require.False(t, diags.HasError())
+			if diags.WarningsCount() > 0 {
+				require.Contains(t, diags[0].Detail(), "has room for 2 more VMs")
+				warnings = append(warnings, i)
+			}
+		}
+		require.Equal(t, []int{2, 3, 4}, warnings)
+	})
+
+	t.Run("VM planned again", func(t *testing.T) {
+		c := newCatalog()
+		for range 3 {
+			var diags diag.Diagnostics
+			checkFlexvmVMCapacity(context.Background(), c, modifyPlanRequest(t, NewFlexvmVMResource(), vm("vm"), nil), &diags)
+			require.Zero(t, diags.WarningsCount())
+		}
+	})
+
+	t.Run("existing VM", func(t *testing.T) {
+		c := newCatalog()
+		for i := range 3 {
+			state := vm(fmt.Sprintf("vm-%d", i))
+			state["id"] = fmt.Sprintf("id-%d", i)
+			var diags diag.Diagnostics
+			checkFlexvmVMCapacity(context.Background(), c, modifyPlanRequest(t, NewFlexvmVMResource(), state, state), &diags)
+			require.Zero(t, diags.WarningsCount())
+		}
+	})
+}
@@ -3,7 +3,6 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
 
 	"terraform-provider-i3dnet/internal/one_api"
 
@@ -101,28 +100,19 @@ func (d *flexvmCloudDataSource) Read(ctx context.Context, req datasource.ReadReq
 		return
 	}
 
-	cloudResp, err := d.client.FlexvmGetCloud(ctx, data.ID.ValueString())
+	cloud, err := d.client.FlexvmGetCloud(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading FlexVM Cloud",
-			"Could not read FlexVM Cloud id "+data.ID.ValueString()+": "+err.Error(),
-		)
-		return
-	}
-
-	if cloudResp.ErrorResponse != nil {
-		if cloudResp.ErrorResponse.StatusCode == http.StatusNotFound {
+		if one_api.IsNotFound(err) {
 			resp.Diagnostics.AddError(
 				"FlexVM Cloud not found",
 				fmt.Sprintf("No FlexVM Cloud found for id %s", data.ID.ValueString()),
 			)
 			return
 		}
-		AddErrorResponseToDiags("Error reading FlexVM Cloud", cloudResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error reading FlexVM Cloud", err, &resp.Diagnostics)
 		return
 	}
 
-	cloud := cloudResp.Cloud
 	data.ID = types.StringValue(cloud.ID)
 	data.Name = types.StringValue(cloud.Name)
 	data.Site = types.StringValue(cloud.Site)
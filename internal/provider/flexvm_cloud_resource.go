@@ -3,7 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"strings"
 	"time"
 
 	"terraform-provider-i3dnet/internal/one_api"
@@ -18,9 +18,11 @@ import (
 )
 
 var (
-	_ resource.Resource                = (*flexvmCloudResource)(nil)
-	_ resource.ResourceWithConfigure   = (*flexvmCloudResource)(nil)
-	_ resource.ResourceWithImportState = (*flexvmCloudResource)(nil)
+	_ resource.Resource                   = (*flexvmCloudResource)(nil)
+	_ resource.ResourceWithConfigure      = (*flexvmCloudResource)(nil)
+	_ resource.ResourceWithImportState    = (*flexvmCloudResource)(nil)
+	_ resource.ResourceWithValidateConfig = (*flexvmCloudResource)(nil)
+	_ resource.ResourceWithModifyPlan     = (*flexvmCloudResource)(nil)
 )
 
 func NewFlexvmCloudResource() resource.Resource {
@@ -28,7 +30,8 @@ func NewFlexvmCloudResource() resource.Resource {
 }
 
 type flexvmCloudResource struct {
-	client *one_api.Client
+	client  *one_api.Client
+	catalog *catalog
 }
 
 type FlexvmCloudModel struct {
@@ -42,7 +45,9 @@ type FlexvmCloudModel struct {
 }
 
 func (r *flexvmCloudResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+	if data := fromProviderData(req.ProviderData, &resp.Diagnostics); data != nil {
+		r.client, r.catalog = data.client, data.catalog
+	}
 }
 
 func (r *flexvmCloudResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -70,8 +75,9 @@ func (r *flexvmCloudResource) Schema(ctx context.Context, req resource.SchemaReq
 				},
 			},
 			"instance_type": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "The FlexMetal instance type shared by every node in the Cloud.",
+				Required: true,
+				MarkdownDescription: "The FlexMetal instance type shared by every node in the Cloud. It is checked against the " +
+					"instance types of the FlexMetal locations when planning.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -106,6 +112,32 @@ func (r *flexvmCloudResource) Schema(ctx context.Context, req resource.SchemaReq
 	}
 }
 
+// ValidateConfig checks site against the known FlexVM sites, suggesting the
+// closest one on a typo.
+func (r *flexvmCloudResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var site types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("site"), &site)...)
+	if resp.Diagnostics.HasError() || site.IsNull() || site.IsUnknown() {
+		return
+	}
+
+	if msg, ok := checkName(site.ValueString(), flexvmSites, "FlexVM site",
+		fmt.Sprintf("Valid sites are: %s.", strings.Join(flexvmSites, ", "))); !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("site"), "Invalid FlexVM site", msg)
+	}
+}
+
+// ModifyPlan checks instance_type against the FlexMetal instance types, so
+// that a typo fails the plan rather than the apply.
+func (r *flexvmCloudResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	validatePlannedCatalogName(ctx, req, path.Root("instance_type"), &r.catalog.flexmetalInstanceTypes,
+		"FlexMetal instance type", "", &resp.Diagnostics)
+}
+
 func (r *flexvmCloudResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data FlexvmCloudModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -129,20 +161,13 @@ func (r *flexvmCloudResource) Create(ctx context.Context, req resource.CreateReq
 		Description:  data.Description.ValueString(),
 	}
 
-	cloudResp, err := r.client.FlexvmCreateCloud(ctx, createReq)
+	cloud, err := r.client.FlexvmCreateCloud(ctx, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating FlexVM Cloud",
-			fmt.Sprintf("Unexpected error: %v", err),
-		)
-		return
-	}
-	if cloudResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating FlexVM Cloud", cloudResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error creating FlexVM Cloud", err, &resp.Diagnostics)
 		return
 	}
 
-	flexvmCloudRespToState(cloudResp.Cloud, &data)
+	flexvmCloudRespToState(cloud, &data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -153,25 +178,17 @@ func (r *flexvmCloudResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
-	cloudResp, err := r.client.FlexvmGetCloud(ctx, data.ID.ValueString())
+	cloud, err := r.client.FlexvmGetCloud(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading FlexVM Cloud",
-			"Could not read FlexVM Cloud id "+data.ID.ValueString()+": "+err.Error(),
-		)
-		return
-	}
-
-	if cloudResp.ErrorResponse != nil {
-		if cloudResp.ErrorResponse.StatusCode == http.StatusNotFound {
+		if one_api.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		AddErrorResponseToDiags("Error reading FlexVM Cloud", cloudResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error reading FlexVM Cloud", err, &resp.Diagnostics)
 		return
 	}
 
-	flexvmCloudRespToState(cloudResp.Cloud, &data)
+	flexvmCloudRespToState(cloud, &data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -196,21 +213,13 @@ func (r *flexvmCloudResource) Delete(ctx context.Context, req resource.DeleteReq
 	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
 	defer cancel()
 
-	cloudResp, err := r.client.FlexvmDeleteCloud(ctx, data.ID.ValueString())
+	err := r.client.FlexvmDeleteCloud(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting FlexVM Cloud",
-			"Could not delete FlexVM Cloud: "+err.Error(),
-		)
-		return
-	}
-
-	if cloudResp.ErrorResponse != nil {
 		// Already gone; nothing left to do.
-		if cloudResp.ErrorResponse.StatusCode == http.StatusNotFound {
+		if one_api.IsNotFound(err) {
 			return
 		}
-		AddErrorResponseToDiags("Error deleting FlexVM Cloud", cloudResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error deleting FlexVM Cloud", err, &resp.Diagnostics)
 		return
 	}
 }
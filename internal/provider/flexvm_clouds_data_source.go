@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexvmCloudsDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexvmCloudsDataSource)(nil)
+)
+
+func NewFlexvmCloudsDataSource() datasource.DataSource {
+	return &flexvmCloudsDataSource{}
+}
+
+// flexvmCloudsDataSource lists the FlexVM Clouds of the organization, so that
+// a Cloud can be found by name rather than by UUID.
+type flexvmCloudsDataSource struct {
+	client *one_api.Client
+}
+
+type flexvmCloudsDataSourceModel struct {
+	NameRegex    types.String `tfsdk:"name_regex"`
+	Site         types.String `tfsdk:"site"`
+	InstanceType types.String `tfsdk:"instance_type"`
+	Clouds       types.List   `tfsdk:"clouds"`
+}
+
+var flexvmCloudObjectAttrTypes = map[string]attr.Type{
+	"id":            types.StringType,
+	"name":          types.StringType,
+	"site":          types.StringType,
+	"instance_type": types.StringType,
+	"description":   types.StringType,
+	"created_at":    types.StringType,
+}
+
+func (d *flexvmCloudsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexvmCloudsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_clouds"
+}
+
+func (d *flexvmCloudsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get all i3D.net FlexVM Clouds of your organization, optionally filtered. This is useful " +
+			"for finding a Cloud by name instead of passing its UUID around.",
+		Attributes: map[string]schema.Attribute{
+			"name_regex": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include Clouds whose name matches this regular expression, e.g. `^team-a-`.",
+			},
+			"site": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include Clouds located in this site, e.g. `frmtl1`.",
+			},
+			"instance_type": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include Clouds whose nodes are of this FlexMetal instance type.",
+			},
+			"clouds": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching Clouds.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Cloud UUID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Cloud name.",
+						},
+						"site": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The i3D site (location) in which the Cloud is located.",
+						},
+						"instance_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The FlexMetal instance type shared by every node in the Cloud.",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Free-form description of the Cloud.",
+						},
+						"created_at": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "When the Cloud was created (RFC3339).",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexvmCloudsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexvmCloudsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := flexvmCloudFilter{
+		site:         data.Site.ValueString(),
+		instanceType: data.InstanceType.ValueString(),
+	}
+	filter.nameRegex = compileNameRegex(data.NameRegex, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clouds, err := d.client.FlexvmListClouds(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexVM Clouds", err, &resp.Diagnostics)
+		return
+	}
+
+	cloudValues := []attr.Value{}
+	for _, cloud := range filter.apply(clouds) {
+		obj, diags := types.ObjectValue(flexvmCloudObjectAttrTypes, map[string]attr.Value{
+			"id":            types.StringValue(cloud.ID),
+			"name":          types.StringValue(cloud.Name),
+			"site":          types.StringValue(cloud.Site),
+			"instance_type": types.StringValue(cloud.InstanceType),
+			"description":   types.StringValue(cloud.Description),
+			"created_at":    types.StringValue(cloud.CreatedAt),
+		})
+		resp.Diagnostics.Append(diags...)
+		cloudValues = append(cloudValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cloudsList, diags := types.ListValue(types.ObjectType{AttrTypes: flexvmCloudObjectAttrTypes}, cloudValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Clouds = cloudsList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// flexvmCloudFilter selects Clouds by the filters of the clouds data source.
+type flexvmCloudFilter struct {
+	nameRegex    *regexp.Regexp
+	site         string
+	instanceType string
+}
+
+// apply returns the Clouds matching f, in the order of the API.
+func (f flexvmCloudFilter) apply(clouds []one_api.FlexvmCloudObj) []one_api.FlexvmCloudObj {
+	return slices.DeleteFunc(slices.Clone(clouds), func(cloud one_api.FlexvmCloudObj) bool {
+		switch {
+		case f.nameRegex != nil && !f.nameRegex.MatchString(cloud.Name):
+			return true
+		case f.site != "" && cloud.Site != f.site:
+			return true
+		case f.instanceType != "" && cloud.InstanceType != f.instanceType:
+			return true
+		}
+		return false
+	})
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexvmCloudsDataSource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+resource "i3dnet_flexvm_cloud" "test" {
+  name          = "terraform-gh-workflows-clouds-data-source-test"
+  site          = "frmtl1"
+  instance_type = "bm9.hmm.gpu.4rtx4000.64"
+}
+
+data "i3dnet_flexvm_clouds" "test" {
+  name_regex = "^terraform-gh-workflows-clouds-data-source-test$"
+  site       = "frmtl1"
+
+  depends_on = [i3dnet_flexvm_cloud.test]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_clouds.test", "clouds.#", "1"),
+					resource.TestCheckResourceAttrPair("data.i3dnet_flexvm_clouds.test", "clouds.0.id",
+						"i3dnet_flexvm_cloud.test", "id"),
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_clouds.test", "clouds.0.site", "frmtl1"),
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_clouds.test", "clouds.0.instance_type",
+						"bm9.hmm.gpu.4rtx4000.64"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_clouds.test", "clouds.0.created_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlexvmCloudFilter(t *testing.T) {
+	t.Parallel()
+
+	clouds := []one_api.FlexvmCloudObj{
+		{Name: "team-a-build", Site: "frmtl1", InstanceType: "bm9.hmm.gpu.4rtx4000.64"},
+		{Name: "team-a-test", Site: "camtr6", InstanceType: "bm9.hmm.gpu.4rtx4000.64"},
+		{Name: "team-b-build", Site: "frmtl1", InstanceType: "bm9.std.16"},
+	}
+
+	tests := []struct {
+		name   string
+		filter flexvmCloudFilter
+		want   []string
+	}{
+		{
+			name:   "no filter keeps the API order",
+			filter: flexvmCloudFilter{},
+			want:   []string{"team-a-build", "team-a-test", "team-b-build"},
+		},
+		{
+			name:   "name regex",
+			filter: flexvmCloudFilter{nameRegex: regexp.MustCompile(`^team-a-`)},
+			want:   []string{"team-a-build", "team-a-test"},
+		},
+		{
+			name:   "site and instance type",
+			filter: flexvmCloudFilter{site: "frmtl1", instanceType: "bm9.std.16"},
+			want:   []string{"team-b-build"},
+		},
+		{
+			name:   "no match",
+			filter: flexvmCloudFilter{site: "nlrtm1"},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			names := []string{}
+			for _, cloud := range tt.filter.apply(clouds) {
+				names = append(names, cloud.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexvmImagesDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexvmImagesDataSource)(nil)
+)
+
+func NewFlexvmImagesDataSource() datasource.DataSource {
+	return &flexvmImagesDataSource{}
+}
+
+// flexvmImagesDataSource lists the images FlexVM VMs can be created from,
+// optionally filtered and narrowed down to the most recent one.
+type flexvmImagesDataSource struct {
+	client *one_api.Client
+}
+
+type flexvmImagesDataSourceModel struct {
+	NameRegex  types.String `tfsdk:"name_regex"`
+	OS         types.String `tfsdk:"os"`
+	OSType     types.String `tfsdk:"os_type"`
+	MostRecent types.Bool   `tfsdk:"most_recent"`
+	Images     types.List   `tfsdk:"images"`
+}
+
+var flexvmImageObjectAttrTypes = map[string]attr.Type{
+	"name":    types.StringType,
+	"os":      types.StringType,
+	"os_type": types.StringType,
+}
+
+func (d *flexvmImagesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexvmImagesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_images"
+}
+
+func (d *flexvmImagesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the images FlexVM VMs can be created from. Use the filters to find a valid " +
+			"`image_name` for `i3dnet_flexvm_vm` without hard-coding it.",
+		Attributes: map[string]schema.Attribute{
+			"name_regex": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include images whose name matches this regular expression, e.g. `^ubuntu-`.",
+			},
+			"os": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include images whose OS name contains this value, ignoring case, e.g. `ubuntu`.",
+			},
+			"os_type": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include images of this OS type: `linux` or `windows`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("linux", "windows"),
+				},
+			},
+			"most_recent": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Only return the most recent of the matching images, which is the one with the " +
+					"highest version in its name, e.g. `ubuntu-2404-server-amd64` over `ubuntu-2204-server-amd64`. " +
+					"An error is returned when no image matches.",
+			},
+			"images": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching images, sorted by name.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Image name, to use as `image_name` of `i3dnet_flexvm_vm`.",
+						},
+						"os": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the OS that the image represents.",
+						},
+						"os_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The OS type. Can be \"linux\" or \"windows\".",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexvmImagesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexvmImagesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := flexvmImageFilter{
+		os:         data.OS.ValueString(),
+		osType:     data.OSType.ValueString(),
+		mostRecent: data.MostRecent.ValueBool(),
+	}
+	filter.nameRegex = compileNameRegex(data.NameRegex, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	images, err := d.client.FlexvmListImages(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexVM images", err, &resp.Diagnostics)
+		return
+	}
+
+	images = filter.apply(images)
+	if filter.mostRecent && len(images) == 0 {
+		resp.Diagnostics.AddError(
+			"No FlexVM image found",
+			"No FlexVM image matches the filters. Remove most_recent to get an empty list instead.",
+		)
+		return
+	}
+
+	imageValues := make([]attr.Value, 0, len(images))
+	for _, image := range images {
+		obj, diags := types.ObjectValue(flexvmImageObjectAttrTypes, map[string]attr.Value{
+			"name":    types.StringValue(image.Name),
+			"os":      types.StringValue(image.OS),
+			"os_type": types.StringValue(image.OSType),
+		})
+		resp.Diagnostics.Append(diags...)
+		imageValues = append(imageValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	imagesList, diags := types.ListValue(types.ObjectType{AttrTypes: flexvmImageObjectAttrTypes}, imageValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Images = imagesList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// flexvmImageFilter selects images by the filters of the images data source.
+type flexvmImageFilter struct {
+	nameRegex  *regexp.Regexp
+	os         string
+	osType     string
+	mostRecent bool
+}
+
+// apply returns the images matching f, sorted by name, or only the most
+// recent one when f.mostRecent is set.
+func (f flexvmImageFilter) apply(images []one_api.FlexvmImage) []one_api.FlexvmImage {
+	matched := slices.DeleteFunc(slices.Clone(images), func(image one_api.FlexvmImage) bool {
+		switch {
+		case f.nameRegex != nil && !f.nameRegex.MatchString(image.Name):
+			return true
+		case f.os != "" && !strings.Contains(strings.ToLower(image.OS), strings.ToLower(f.os)):
+			return true
+		case f.osType != "" && image.OSType != f.osType:
+			return true
+		}
+		return false
+	})
+
+	slices.SortFunc(matched, func(a, b one_api.FlexvmImage) int {
+		return compareNatural(a.Name, b.Name)
+	})
+
+	if f.mostRecent && len(matched) > 0 {
+		return matched[len(matched)-1:]
+	}
+
+	return matched
+}
+
+// compileNameRegex compiles the name_regex attribute of a data source. It
+// returns nil when the attribute is not set, and adds an attribute error when
+// it is not a valid regular expression.
+func compileNameRegex(value types.String, diags *diag.Diagnostics) *regexp.Regexp {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
+
+	re, err := regexp.Compile(value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("name_regex"), "Invalid name_regex", err.Error())
+		return nil
+	}
+
+	return re
+}
+
+// compareNatural compares two names the way a human would: runs of digits
+// are compared by their numeric value, so "ubuntu-2404" sorts after
+// "ubuntu-2204" and "vm.16c" after "vm.8c".
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		aDigits, bDigits := unicode.IsDigit(rune(a[0])), unicode.IsDigit(rune(b[0]))
+		if aDigits != bDigits {
+			return strings.Compare(a, b)
+		}
+
+		aRun, bRun := leadingRun(a, aDigits), leadingRun(b, bDigits)
+		if aDigits {
+			// Compare numbers by length first, ignoring leading zeros.
+			aNum, bNum := strings.TrimLeft(aRun, "0"), strings.TrimLeft(bRun, "0")
+			if c := len(aNum) - len(bNum); c != 0 {
+				return c
+			}
+			if c := strings.Compare(aNum, bNum); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(aRun, bRun); c != 0 {
+			return c
+		}
+
+		a, b = a[len(aRun):], b[len(bRun):]
+	}
+
+	return len(a) - len(b)
+}
+
+// leadingRun returns the leading run of s of digits, or of non-digits.
+func leadingRun(s string, digits bool) string {
+	for i, r := range s {
+		if unicode.IsDigit(r) != digits {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexvmImagesDataSource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_images" "all" {}
+
+data "i3dnet_flexvm_images" "ubuntu" {
+  os          = "ubuntu"
+  os_type     = "linux"
+  most_recent = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_images.all", "images.0.name"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_images.all", "images.0.os_type"),
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_images.ubuntu", "images.#", "1"),
+					resource.TestMatchResourceAttr("data.i3dnet_flexvm_images.ubuntu", "images.0.name", regexp.MustCompile(`^ubuntu-`)),
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_images.ubuntu", "images.0.os_type", "linux"),
+				),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_images" "none" {
+  name_regex  = "^does-not-exist$"
+  most_recent = true
+}
+`,
+				ExpectError: regexp.MustCompile(`No FlexVM image found`),
+			},
+		},
+	})
+}
+
+func TestFlexvmImageFilter(t *testing.T) {
+	t.Parallel()
+
+	images := []one_api.FlexvmImage{
+		{Name: "ubuntu-2404-server-amd64", OS: "Ubuntu LTS 24.04", OSType: "linux"},
+		{Name: "windows-2022-server-amd64", OS: "Windows Server 2022", OSType: "windows"},
+		{Name: "ubuntu-2204-server-amd64", OS: "Ubuntu LTS 22.04", OSType: "linux"},
+		{Name: "debian-12-server-amd64", OS: "Debian 12", OSType: "linux"},
+		{Name: "debian-9-server-amd64", OS: "Debian 9", OSType: "linux"},
+	}
+
+	tests := []struct {
+		name   string
+		filter flexvmImageFilter
+		want   []string
+	}{
+		{
+			name:   "no filter sorts by name",
+			filter: flexvmImageFilter{},
+			want: []string{
+				"debian-9-server-amd64",
+				"debian-12-server-amd64",
+				"ubuntu-2204-server-amd64",
+				"ubuntu-2404-server-amd64",
+				"windows-2022-server-amd64",
+			},
+		},
+		{
+			name:   "os ignores case",
+			filter: flexvmImageFilter{os: "UBUNTU"},
+			want:   []string{"ubuntu-2204-server-amd64", "ubuntu-2404-server-amd64"},
+		},
+		{
+			name:   "os type",
+			filter: flexvmImageFilter{osType: "windows"},
+			want:   []string{"windows-2022-server-amd64"},
+		},
+		{
+			name:   "most recent compares versions numerically",
+			filter: flexvmImageFilter{nameRegex: regexp.MustCompile(`^debian-`), mostRecent: true},
+			want:   []string{"debian-12-server-amd64"},
+		},
+		{
+			name:   "most recent without match",
+			filter: flexvmImageFilter{os: "centos", mostRecent: true},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			names := []string{}
+			for _, image := range tt.filter.apply(images) {
+				names = append(names, image.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestCompareNatural(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "debian-9", b: "debian-12", want: -1},
+		{a: "ubuntu-2404", b: "ubuntu-2204", want: 1},
+		{a: "vm.16c", b: "vm.8c", want: 1},
+		{a: "img-007", b: "img-7", want: 0},
+		{a: "img", b: "img-1", want: -1},
+		{a: "a1", b: "ab", want: -1},
+	}
+
+	for _, tt := range tests {
+		got := compareNatural(tt.a, tt.b)
+		require.Equalf(t, tt.want, sign(got), "compareNatural(%q, %q) = %d", tt.a, tt.b, got)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexvmInstanceTypesDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexvmInstanceTypesDataSource)(nil)
+)
+
+func NewFlexvmInstanceTypesDataSource() datasource.DataSource {
+	return &flexvmInstanceTypesDataSource{}
+}
+
+// flexvmInstanceTypesDataSource lists the instance types FlexVM VMs can be
+// based on, optionally filtered and narrowed down to the smallest one.
+type flexvmInstanceTypesDataSource struct {
+	client *one_api.Client
+}
+
+type flexvmInstanceTypesDataSourceModel struct {
+	NameRegex     types.String `tfsdk:"name_regex"`
+	MinVCPU       types.Int64  `tfsdk:"min_vcpu"`
+	MinMemory     types.Int64  `tfsdk:"min_memory"`
+	MinDisk       types.Int64  `tfsdk:"min_disk"`
+	PCIDeviceType types.String `tfsdk:"pci_device_type"`
+	Smallest      types.Bool   `tfsdk:"smallest"`
+	InstanceTypes types.List   `tfsdk:"instance_types"`
+}
+
+var flexvmPCIDeviceObjectAttrTypes = map[string]attr.Type{
+	"type": types.StringType,
+	"spec": types.StringType,
+}
+
+var flexvmInstanceTypeObjectAttrTypes = map[string]attr.Type{
+	"id":          types.StringType,
+	"name":        types.StringType,
+	"vcpu":        types.Int64Type,
+	"memory":      types.Int64Type,
+	"disk":        types.Int64Type,
+	"pci_devices": types.ListType{ElemType: types.ObjectType{AttrTypes: flexvmPCIDeviceObjectAttrTypes}},
+}
+
+func (d *flexvmInstanceTypesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexvmInstanceTypesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_instance_types"
+}
+
+func (d *flexvmInstanceTypesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the instance types FlexVM VMs can be based on. Use the filters to find a valid " +
+			"`instance_type_name` for `i3dnet_flexvm_vm` without hard-coding it.",
+		Attributes: map[string]schema.Attribute{
+			"name_regex": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types whose name matches this regular expression, e.g. `^vm\\.`.",
+			},
+			"min_vcpu": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with at least this many virtual CPUs.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"min_memory": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with at least this much memory, in MB.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"min_disk": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with at least this much disk, in GB.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"pci_device_type": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include instance types with a PCI passthrough device of this type: `gpu` or `nvme`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("gpu", "nvme"),
+				},
+			},
+			"smallest": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Only return the smallest of the matching instance types, comparing virtual CPUs, " +
+					"then memory, then disk. An error is returned when no instance type matches.",
+			},
+			"instance_types": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching instance types, sorted from smallest to largest.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Instance type UUID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Instance type name, to use as `instance_type_name` of `i3dnet_flexvm_vm`.",
+						},
+						"vcpu": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of virtual CPUs.",
+						},
+						"memory": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Memory in MB.",
+						},
+						"disk": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Disk size in GB.",
+						},
+						"pci_devices": schema.ListNestedAttribute{
+							Computed:            true,
+							MarkdownDescription: "PCI passthrough devices of the instance type.",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"type": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "Device type. Can be \"gpu\" or \"nvme\".",
+									},
+									"spec": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "Device specification, e.g. `rtx4000`.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexvmInstanceTypesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexvmInstanceTypesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := flexvmInstanceTypeFilter{
+		minVCPU:       int(data.MinVCPU.ValueInt64()),
+		minMemory:     int(data.MinMemory.ValueInt64()),
+		minDisk:       int(data.MinDisk.ValueInt64()),
+		pciDeviceType: data.PCIDeviceType.ValueString(),
+		smallest:      data.Smallest.ValueBool(),
+	}
+	filter.nameRegex = compileNameRegex(data.NameRegex, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	instanceTypes, err := d.client.FlexvmListInstanceTypes(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexVM instance types", err, &resp.Diagnostics)
+		return
+	}
+
+	instanceTypes = filter.apply(instanceTypes)
+	if filter.smallest && len(instanceTypes) == 0 {
+		resp.Diagnostics.AddError(
+			"No FlexVM instance type found",
+			"No FlexVM instance type matches the filters. Remove smallest to get an empty list instead.",
+		)
+		return
+	}
+
+	pciDeviceType := types.ObjectType{AttrTypes: flexvmPCIDeviceObjectAttrTypes}
+	instanceTypeValues := make([]attr.Value, 0, len(instanceTypes))
+	for _, it := range instanceTypes {
+		deviceValues := make([]attr.Value, 0, len(it.PCIDevices))
+		for _, device := range it.PCIDevices {
+			obj, diags := types.ObjectValue(flexvmPCIDeviceObjectAttrTypes, map[string]attr.Value{
+				"type": types.StringValue(device.Type),
+				"spec": types.StringValue(device.Spec),
+			})
+			resp.Diagnostics.Append(diags...)
+			deviceValues = append(deviceValues, obj)
+		}
+		devices, diags := types.ListValue(pciDeviceType, deviceValues)
+		resp.Diagnostics.Append(diags...)
+
+		obj, diags := types.ObjectValue(flexvmInstanceTypeObjectAttrTypes, map[string]attr.Value{
+			"id":          types.StringValue(it.ID),
+			"name":        types.StringValue(it.Name),
+			"vcpu":        types.Int64Value(int64(it.VCPU)),
+			"memory":      types.Int64Value(int64(it.Memory)),
+			"disk":        types.Int64Value(int64(it.Disk)),
+			"pci_devices": devices,
+		})
+		resp.Diagnostics.Append(diags...)
+		instanceTypeValues = append(instanceTypeValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	instanceTypesList, diags := types.ListValue(types.ObjectType{AttrTypes: flexvmInstanceTypeObjectAttrTypes}, instanceTypeValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.InstanceTypes = instanceTypesList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// flexvmInstanceTypeFilter selects instance types by the filters of the
+// instance types data source.
+type flexvmInstanceTypeFilter struct {
+	nameRegex     *regexp.Regexp
+	minVCPU       int
+	minMemory     int
+	minDisk       int
+	pciDeviceType string
+	smallest      bool
+}
+
+// apply returns the instance types matching f, sorted from smallest to
+// largest, or only the smallest one when f.smallest is set.
+func (f flexvmInstanceTypeFilter) apply(instanceTypes []one_api.FlexvmInstanceType) []one_api.FlexvmInstanceType {
+	matched := slices.DeleteFunc(slices.Clone(instanceTypes), func(it one_api.FlexvmInstanceType) bool {
+		switch {
+		case f.nameRegex != nil && !f.nameRegex.MatchString(it.Name):
+			return true
+		case it.VCPU < f.minVCPU || it.Memory < f.minMemory || it.Disk < f.minDisk:
+			return true
+		case f.pciDeviceType != "" && !slices.ContainsFunc(it.PCIDevices, func(device one_api.FlexvmPCIDevice) bool {
+			return device.Type == f.pciDeviceType
+		}):
+			return true
+		}
+		return false
+	})
+
+	slices.SortFunc(matched, func(a, b one_api.FlexvmInstanceType) int {
+		return cmp.Or(
+			cmp.Compare(a.VCPU, b.VCPU),
+			cmp.Compare(a.Memory, b.Memory),
+			cmp.Compare(a.Disk, b.Disk),
+			compareNatural(a.Name, b.Name),
+		)
+	})
+
+	if f.smallest && len(matched) > 0 {
+		return matched[:1]
+	}
+
+	return matched
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexvmInstanceTypesDataSource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_instance_types" "all" {}
+
+data "i3dnet_flexvm_instance_types" "small" {
+  min_vcpu   = 4
+  min_memory = 8192
+  smallest   = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_instance_types.all", "instance_types.0.id"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_instance_types.all", "instance_types.0.name"),
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_instance_types.small", "instance_types.#", "1"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_instance_types.small", "instance_types.0.name"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_instance_types.small", "instance_types.0.vcpu"),
+				),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_instance_types" "none" {
+  min_vcpu = 100000
+  smallest = true
+}
+`,
+				ExpectError: regexp.MustCompile(`No FlexVM instance type found`),
+			},
+		},
+	})
+}
+
+func TestFlexvmInstanceTypeFilter(t *testing.T) {
+	t.Parallel()
+
+	instanceTypes := []one_api.FlexvmInstanceType{
+		{Name: "vm.gpu.1rtx4000.15c.248g", VCPU: 15, Memory: 253952, Disk: 500, PCIDevices: []one_api.FlexvmPCIDevice{{Type: "gpu", Spec: "rtx4000"}}},
+		{Name: "vm.8c.16g", VCPU: 8, Memory: 16384, Disk: 160},
+		{Name: "vm.4c.16g", VCPU: 4, Memory: 16384, Disk: 80},
+		{Name: "vm.4c.8g", VCPU: 4, Memory: 8192, Disk: 80},
+		{Name: "vm.2c.4g", VCPU: 2, Memory: 4096, Disk: 40},
+	}
+
+	tests := []struct {
+		name   string
+		filter flexvmInstanceTypeFilter
+		want   []string
+	}{
+		{
+			name:   "no filter sorts by size",
+			filter: flexvmInstanceTypeFilter{},
+			want:   []string{"vm.2c.4g", "vm.4c.8g", "vm.4c.16g", "vm.8c.16g", "vm.gpu.1rtx4000.15c.248g"},
+		},
+		{
+			name:   "minimum sizes",
+			filter: flexvmInstanceTypeFilter{minVCPU: 4, minMemory: 16000},
+			want:   []string{"vm.4c.16g", "vm.8c.16g", "vm.gpu.1rtx4000.15c.248g"},
+		},
+		{
+			name:   "smallest",
+			filter: flexvmInstanceTypeFilter{minDisk: 80, smallest: true},
+			want:   []string{"vm.4c.8g"},
+		},
+		{
+			name:   "pci device type",
+			filter: flexvmInstanceTypeFilter{pciDeviceType: "gpu"},
+			want:   []string{"vm.gpu.1rtx4000.15c.248g"},
+		},
+		{
+			name:   "name regex",
+			filter: flexvmInstanceTypeFilter{nameRegex: regexp.MustCompile(`^vm\.\d+c\.16g$`)},
+			want:   []string{"vm.4c.16g", "vm.8c.16g"},
+		},
+		{
+			name:   "smallest without match",
+			filter: flexvmInstanceTypeFilter{pciDeviceType: "nvme", smallest: true},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			names := []string{}
+			for _, it := range tt.filter.apply(instanceTypes) {
+				names = append(names, it.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
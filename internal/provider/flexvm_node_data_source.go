@@ -3,7 +3,6 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
 
 	"terraform-provider-i3dnet/internal/one_api"
 
@@ -79,28 +78,19 @@ func (d *flexvmNodeDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		return
 	}
 
-	nodeResp, err := d.client.FlexvmGetNode(ctx, data.CloudID.ValueString(), data.ID.ValueString())
+	node, err := d.client.FlexvmGetNode(ctx, data.CloudID.ValueString(), data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading FlexVM Cloud Node",
-			"Could not read FlexVM Cloud Node id "+data.ID.ValueString()+": "+err.Error(),
-		)
-		return
-	}
-
-	if nodeResp.ErrorResponse != nil {
-		if nodeResp.ErrorResponse.StatusCode == http.StatusNotFound {
+		if one_api.IsNotFound(err) {
 			resp.Diagnostics.AddError(
 				"FlexVM Cloud Node not found",
 				fmt.Sprintf("No FlexVM Cloud Node found for cloud_id %s and id %s", data.CloudID.ValueString(), data.ID.ValueString()),
 			)
 			return
 		}
-		AddErrorResponseToDiags("Error reading FlexVM Cloud Node", nodeResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error reading FlexVM Cloud Node", err, &resp.Diagnostics)
 		return
 	}
 
-	node := nodeResp.Node
 	data.ID = types.StringValue(node.ID)
 	data.Name = types.StringValue(node.Name)
 	data.Serial = types.StringValue(node.Serial)
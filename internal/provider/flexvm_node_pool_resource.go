@@ -0,0 +1,565 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                = (*flexvmNodePoolResource)(nil)
+	_ resource.ResourceWithConfigure   = (*flexvmNodePoolResource)(nil)
+	_ resource.ResourceWithImportState = (*flexvmNodePoolResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*flexvmNodePoolResource)(nil)
+)
+
+func NewFlexvmNodePoolResource() resource.Resource {
+	return &flexvmNodePoolResource{}
+}
+
+// flexvmNodePoolResource manages a number of Nodes of a Cloud as one unit.
+// The API has no notion of a pool: the pool is the set of Nodes it created,
+// tracked in state.
+type flexvmNodePoolResource struct {
+	client *one_api.Client
+}
+
+type FlexvmNodePoolModel struct {
+	CloudID  types.String   `tfsdk:"cloud_id"`
+	Size     types.Int64    `tfsdk:"size"`
+	Nodes    types.List     `tfsdk:"nodes"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+var flexvmPoolNodeObjectAttrTypes = map[string]attr.Type{
+	"id":     types.StringType,
+	"name":   types.StringType,
+	"serial": types.StringType,
+	"status": types.StringType,
+}
+
+func (r *flexvmNodePoolResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *flexvmNodePoolResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_node_pool"
+}
+
+func (r *flexvmNodePoolResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a pool of bare metal Nodes within an i3D.net FlexVM private cloud, so that a Cloud can be " +
+			"scaled by changing a single `size` attribute. Nodes are added and removed concurrently, and the resource waits " +
+			"until added Nodes reach the `running` status. Only Nodes without VMs are removed: scaling down, or destroying the " +
+			"pool, fails before removing any Node when too few Nodes have no VMs.\n\n" +
+			"Nodes of a pool must not also be managed with `i3dnet_flexvm_node`.",
+		Attributes: map[string]schema.Attribute{
+			"cloud_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "UUID of the Cloud in which to create the Nodes.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"size": schema.Int64Attribute{
+				Required:            true,
+				MarkdownDescription: "Number of Nodes in the pool.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"nodes": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The Nodes of the pool.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Cloud Node UUID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Cloud Node name.",
+						},
+						"serial": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Cloud Node serial number.",
+						},
+						"status": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The status of the Node. One of: `created`, `requested`, `bootstrapping`, `running`, `failed`, `deleting`, `deleted`.",
+						},
+					},
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// ModifyPlan keeps the Nodes of the pool in the plan when its size does not
+// change, e.g. when only the timeouts do.
+func (r *flexvmNodePoolResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state FlexvmNodePoolModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Size.Equal(state.Size) && plan.CloudID.Equal(state.CloudID) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("nodes"), state.Nodes)...)
+	}
+}
+
+func (r *flexvmNodePoolResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FlexvmNodePoolModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, 30*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	r.scaleUp(ctx, nil, int(data.Size.ValueInt64()), &resp.State, &data, &resp.Diagnostics)
+}
+
+func (r *flexvmNodePoolResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FlexvmNodePoolModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cloudID := data.CloudID.ValueString()
+
+	nodes, err := r.client.FlexvmListNodes(ctx, cloudID)
+	if err != nil {
+		if one_api.IsNotFound(err) {
+			// The Cloud, and thereby its Nodes, no longer exists.
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		AddErrorResponseToDiags("Error reading FlexVM Cloud Nodes", err, &resp.Diagnostics)
+		return
+	}
+	nodes = slices.DeleteFunc(nodes, func(n one_api.FlexvmNodeObj) bool { return n.Status == "deleted" })
+
+	// An imported pool has no Nodes yet, and adopts every Node of the Cloud.
+	if !data.Nodes.IsNull() {
+		poolNodes, diags := flexvmPoolNodesFromState(ctx, data)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// Nodes that no longer exist are dropped, which shows as a smaller
+		// size in the next plan.
+		cloudNodes := nodes
+		nodes = nil
+		for _, pn := range poolNodes {
+			if i := slices.IndexFunc(cloudNodes, func(n one_api.FlexvmNodeObj) bool { return n.ID == pn.ID }); i >= 0 {
+				nodes = append(nodes, cloudNodes[i])
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(flexvmPoolNodesToState(nodes, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *flexvmNodePoolResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state FlexvmNodePoolModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, 30*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	nodes, diags := flexvmPoolNodesFromState(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	size := int(data.Size.ValueInt64())
+	switch {
+	case size > len(nodes):
+		r.scaleUp(ctx, nodes, size-len(nodes), &resp.State, &data, &resp.Diagnostics)
+	case size < len(nodes):
+		r.scaleDown(ctx, nodes, len(nodes)-size, &resp.State, &data, &resp.Diagnostics)
+	default:
+		// Only the timeouts changed.
+		resp.Diagnostics.Append(flexvmPoolNodesToState(nodes, &data)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	}
+}
+
+func (r *flexvmNodePoolResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FlexvmNodePoolModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	nodes, diags := flexvmPoolNodesFromState(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The pool stays in state, with the Nodes that could not be deleted, when
+	// deleting any Node fails.
+	r.scaleDown(ctx, nodes, len(nodes), &resp.State, &data, &resp.Diagnostics)
+}
+
+func (r *flexvmNodePoolResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Importing by Cloud UUID adopts every Node of the Cloud, see Read.
+	resource.ImportStatePassthroughID(ctx, path.Root("cloud_id"), req, resp)
+}
+
+// scaleUp adds count Nodes to the pool nodes concurrently, and waits until
+// they are running. The pool is saved to state as soon as the Nodes are
+// requested, to prevent dangling Nodes on timeout.
+func (r *flexvmNodePoolResource) scaleUp(ctx context.Context, nodes []one_api.FlexvmNodeObj, count int, state *tfsdk.State, data *FlexvmNodePoolModel, diags *diag.Diagnostics) {
+	cloudID := data.CloudID.ValueString()
+	tflog.Debug(ctx, "Adding Nodes to FlexVM Node pool", map[string]any{"cloud_id": cloudID, "count": count})
+
+	created := make([]one_api.FlexvmNodeObj, count)
+	ok := forEachConcurrently(count, diags, func(i int, diags *diag.Diagnostics) bool {
+		node, err := r.client.FlexvmCreateNode(ctx, cloudID)
+		if err != nil {
+			AddErrorResponseToDiags("Error creating FlexVM Cloud Node", err, diags)
+			return false
+		}
+		created[i] = *node
+		return true
+	})
+	nodes = slices.Concat(nodes, filterByIndex(created, ok))
+
+	if !r.saveNodes(ctx, nodes, state, data, diags) {
+		return
+	}
+
+	// Provisioning continues in the background; wait until the Nodes come up.
+	// Nodes created by previous runs are waited for too, as a timeout may have
+	// interrupted the wait for them.
+	ok = forEachConcurrently(len(nodes), diags, func(i int, diags *diag.Diagnostics) bool {
+		if nodes[i].Status == "running" || nodes[i].Status == "failed" {
+			return true
+		}
+		return r.waitForRunning(ctx, cloudID, &nodes[i], diags)
+	})
+	nodes = filterByIndex(nodes, ok)
+
+	for _, node := range nodes {
+		if node.Status != "running" {
+			diags.AddError(
+				"FlexVM Cloud Node creation failed, node is not running",
+				fmt.Sprintf("Node did not reach 'running' status.\nNode id: %s", node.ID),
+			)
+		}
+	}
+
+	r.saveNodes(ctx, nodes, state, data, diags)
+}
+
+// waitForRunning polls node until it finishes provisioning, updating it with
+// the latest details. It reports whether the node still exists.
+func (r *flexvmNodePoolResource) waitForRunning(ctx context.Context, cloudID string, node *one_api.FlexvmNodeObj, diags *diag.Diagnostics) bool {
+	exists := true
+	err := poll(ctx, 10*time.Second, 30*time.Second, func() bool {
+		latest, terminal, err := fetchNode(ctx, r.client, cloudID, node.ID, true)
+		if err != nil {
+			AddErrorResponseToDiags("Error reading FlexVM Cloud Node", err, diags)
+			return true
+		}
+		if terminal {
+			exists = false
+			return true
+		}
+		*node = *latest
+		return node.Status == "running" || node.Status == "failed"
+	})
+	if err != nil {
+		diags.AddError(
+			"Error waiting for FlexVM Cloud Node to be ready",
+			fmt.Sprintf("Error: %v\nLast status: %s\nNode id: %s", err, node.Status, node.ID),
+		)
+	}
+
+	return exists
+}
+
+// scaleDown removes count Nodes from the pool nodes concurrently, choosing
+// them with flexvmNodesToRemove, and waits until they are deleted.
+func (r *flexvmNodePoolResource) scaleDown(ctx context.Context, nodes []one_api.FlexvmNodeObj, count int, state *tfsdk.State, data *FlexvmNodePoolModel, diags *diag.Diagnostics) {
+	cloudID := data.CloudID.ValueString()
+	tflog.Debug(ctx, "Removing Nodes from FlexVM Node pool", map[string]any{"cloud_id": cloudID, "count": count})
+
+	vms, err := r.client.FlexvmListVMs(ctx, cloudID, "")
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexVM VMs", err, diags)
+		return
+	}
+	vmCounts := map[string]int{}
+	for _, vm := range vms {
+		if isPlacedFlexvmVM(vm) {
+			vmCounts[vm.Node.ID]++
+		}
+	}
+
+	toRemove, err := flexvmNodesToRemove(nodes, vmCounts, count)
+	if err != nil {
+		diags.AddError("FlexVM Cloud Nodes cannot be removed",
+			fmt.Sprintf("%v\nNodes hosting VMs are never removed, as that would take their VMs down. "+
+				"Delete or move the VMs first, or remove fewer Nodes.", err))
+		return
+	}
+
+	removed := forEachConcurrently(len(toRemove), diags, func(i int, diags *diag.Diagnostics) bool {
+		return r.deleteNode(ctx, cloudID, toRemove[i], diags)
+	})
+
+	nodes = slices.DeleteFunc(nodes, func(n one_api.FlexvmNodeObj) bool {
+		i := slices.IndexFunc(toRemove, func(tr one_api.FlexvmNodeObj) bool { return tr.ID == n.ID })
+		return i >= 0 && removed[i]
+	})
+
+	r.saveNodes(ctx, nodes, state, data, diags)
+}
+
+// deleteNode deletes node and waits until it is deleted. It reports whether
+// the node no longer exists.
+func (r *flexvmNodePoolResource) deleteNode(ctx context.Context, cloudID string, node one_api.FlexvmNodeObj, diags *diag.Diagnostics) bool {
+	latest, terminal, err := fetchNode(ctx, r.client, cloudID, node.ID, true)
+	if err != nil {
+		AddErrorResponseToDiags("Error reading FlexVM Cloud Node", err, diags)
+		return false
+	}
+	if terminal {
+		return true
+	}
+
+	if latest.Status != "running" && latest.Status != "failed" {
+		diags.AddError(
+			"FlexVM Cloud Node cannot be deleted",
+			fmt.Sprintf("Node must be in 'running' or 'failed' status to be deleted, but is in '%s' status.\nNode id: %s", latest.Status, node.ID),
+		)
+		return false
+	}
+
+	if err := r.client.FlexvmDeleteNode(ctx, cloudID, node.ID); err != nil {
+		AddErrorResponseToDiags("Error deleting FlexVM Cloud Node", err, diags)
+		return false
+	}
+
+	lastStatus := latest.Status
+	err = poll(ctx, 500*time.Millisecond, 10*time.Second, func() bool {
+		latest, terminal, err = fetchNode(ctx, r.client, cloudID, node.ID, false)
+		if err != nil {
+			AddErrorResponseToDiags("Error reading FlexVM Cloud Node", err, diags)
+			return true
+		}
+		if !terminal {
+			lastStatus = latest.Status
+		}
+		return terminal
+	})
+	if err != nil {
+		diags.AddError(
+			"FlexVM Cloud Node deletion failed",
+			fmt.Sprintf("Error: %v\nLast status: %s\nNode id: %s", err, lastStatus, node.ID),
+		)
+		return false
+	}
+
+	return terminal
+}
+
+// saveNodes writes the pool with nodes to state. It reports whether writing
+// succeeded.
+func (r *flexvmNodePoolResource) saveNodes(ctx context.Context, nodes []one_api.FlexvmNodeObj, state *tfsdk.State, data *FlexvmNodePoolModel, diags *diag.Diagnostics) bool {
+	d := flexvmPoolNodesToState(nodes, data)
+	if !d.HasError() {
+		d.Append(state.Set(ctx, data)...)
+	}
+	diags.Append(d...)
+	return !d.HasError()
+}
+
+// flexvmNodesToRemove returns the count nodes to remove first when scaling
+// down: failed nodes, then the most recently added. Only nodes without VMs
+// according to vmCounts are removed: an error is returned, naming the nodes
+// hosting VMs, when fewer than count nodes have none. Nodes that cannot be
+// deleted in their status come last.
+func flexvmNodesToRemove(nodes []one_api.FlexvmNodeObj, vmCounts map[string]int, count int) ([]one_api.FlexvmNodeObj, error) {
+	rank := func(n one_api.FlexvmNodeObj) int {
+		switch n.Status {
+		case "failed":
+			return 0
+		case "running":
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	count = min(count, len(nodes))
+	candidates := slices.Clone(nodes)
+	slices.Reverse(candidates)
+	candidates = slices.DeleteFunc(candidates, func(n one_api.FlexvmNodeObj) bool { return vmCounts[n.ID] > 0 })
+	if len(candidates) < count {
+		var hosting []string
+		for _, n := range nodes {
+			if vmCounts[n.ID] > 0 {
+				hosting = append(hosting, fmt.Sprintf("%s (%d VMs)", n.ID, vmCounts[n.ID]))
+			}
+		}
+		return nil, fmt.Errorf("%d Nodes must be removed, but only %d have no VMs. Nodes hosting VMs: %s",
+			count, len(candidates), strings.Join(hosting, ", "))
+	}
+
+	slices.SortStableFunc(candidates, func(a, b one_api.FlexvmNodeObj) int {
+		return cmp.Compare(rank(a), rank(b))
+	})
+
+	return candidates[:count], nil
+}
+
+// forEachConcurrently calls f for 0 to n-1 concurrently, and reports for each
+// whether f succeeded. Each call gets its own diagnostics, which are appended
+// to diags once all calls return.
+func forEachConcurrently(n int, diags *diag.Diagnostics, f func(i int, diags *diag.Diagnostics) bool) []bool {
+	ok := make([]bool, n)
+	callDiags := make([]diag.Diagnostics, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok[i] = f(i, &callDiags[i])
+		}()
+	}
+	wg.Wait()
+
+	for _, d := range callDiags {
+		diags.Append(d...)
+	}
+	return ok
+}
+
+// filterByIndex returns the items for which keep is set.
+func filterByIndex[T any](items []T, keep []bool) []T {
+	var kept []T
+	for i, item := range items {
+		if keep[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func flexvmPoolNodesFromState(ctx context.Context, data FlexvmNodePoolModel) ([]one_api.FlexvmNodeObj, diag.Diagnostics) {
+	var models []struct {
+		ID     types.String `tfsdk:"id"`
+		Name   types.String `tfsdk:"name"`
+		Serial types.String `tfsdk:"serial"`
+		Status types.String `tfsdk:"status"`
+	}
+	diags := data.Nodes.ElementsAs(ctx, &models, false)
+
+	nodes := make([]one_api.FlexvmNodeObj, 0, len(models))
+	for _, m := range models {
+		nodes = append(nodes, one_api.FlexvmNodeObj{
+			ID:     m.ID.ValueString(),
+			Name:   m.Name.ValueString(),
+			Serial: m.Serial.ValueString(),
+			Status: m.Status.ValueString(),
+		})
+	}
+	return nodes, diags
+}
+
+// flexvmPoolNodesToState sets the Nodes of the pool, and its size to their
+// number.
+func flexvmPoolNodesToState(nodes []one_api.FlexvmNodeObj, data *FlexvmNodePoolModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	nodeValues := make([]attr.Value, 0, len(nodes))
+	for _, node := range nodes {
+		obj, d := types.ObjectValue(flexvmPoolNodeObjectAttrTypes, map[string]attr.Value{
+			"id":     types.StringValue(node.ID),
+			"name":   types.StringValue(node.Name),
+			"serial": types.StringValue(node.Serial),
+			"status": types.StringValue(node.Status),
+		})
+		diags.Append(d...)
+		nodeValues = append(nodeValues, obj)
+	}
+
+	nodeList, d := types.ListValue(types.ObjectType{AttrTypes: flexvmPoolNodeObjectAttrTypes}, nodeValues)
+	diags.Append(d...)
+	data.Nodes = nodeList
+	data.Size = types.Int64Value(int64(len(nodes)))
+
+	return diags
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlexvmNodesToRemove(t *testing.T) {
+	t.Parallel()
+
+	node := func(id, status string) one_api.FlexvmNodeObj {
+		return one_api.FlexvmNodeObj{ID: id, Status: status}
+	}
+
+	tests := []struct {
+		name     string
+		nodes    []one_api.FlexvmNodeObj
+		vmCounts map[string]int
+		count    int
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:  "most recently added first",
+			nodes: []one_api.FlexvmNodeObj{node("a", "running"), node("b", "running"), node("c", "running")},
+			count: 2,
+			want:  []string{"c", "b"},
+		},
+		{
+			name:     "only nodes without vms",
+			nodes:    []one_api.FlexvmNodeObj{node("a", "running"), node("b", "running"), node("c", "running")},
+			vmCounts: map[string]int{"c": 1},
+			count:    2,
+			want:     []string{"b", "a"},
+		},
+		{
+			name:  "failed nodes first",
+			nodes: []one_api.FlexvmNodeObj{node("a", "failed"), node("b", "running")},
+			count: 1,
+			want:  []string{"a"},
+		},
+		{
+			name:     "failed nodes with vms are kept",
+			nodes:    []one_api.FlexvmNodeObj{node("a", "failed"), node("b", "running")},
+			vmCounts: map[string]int{"a": 1},
+			count:    1,
+			want:     []string{"b"},
+		},
+		{
+			name:  "nodes that cannot be deleted last",
+			nodes: []one_api.FlexvmNodeObj{node("a", "running"), node("b", "bootstrapping")},
+			count: 1,
+			want:  []string{"a"},
+		},
+		{
+			name:  "all nodes",
+			nodes: []one_api.FlexvmNodeObj{node("a", "running"), node("b", "running")},
+			count: 3,
+			want:  []string{"b", "a"},
+		},
+		{
+			name:     "too few nodes without vms",
+			nodes:    []one_api.FlexvmNodeObj{node("a", "running"), node("b", "running"), node("c", "failed")},
+			vmCounts: map[string]int{"b": 2, "c": 1},
+			count:    2,
+			wantErr:  "2 Nodes must be removed, but only 1 have no VMs. Nodes hosting VMs: b (2 VMs), c (1 VMs)",
+		},
+		{
+			name:     "only nodes with vms",
+			nodes:    []one_api.FlexvmNodeObj{node("a", "running"), node("b", "running")},
+			vmCounts: map[string]int{"a": 1, "b": 1},
+			count:    1,
+			wantErr:  "1 Nodes must be removed, but only 0 have no VMs. Nodes hosting VMs: a (1 VMs), b (1 VMs)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			toRemove, err := flexvmNodesToRemove(tt.nodes, tt.vmCounts, tt.count)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, namesOf(toRemove, func(n one_api.FlexvmNodeObj) string { return n.ID }))
+		})
+	}
+}
+
+func TestForEachConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var diags diag.Diagnostics
+	ok := forEachConcurrently(4, &diags, func(i int, diags *diag.Diagnostics) bool {
+		calls.Add(1)
+		if i%2 == 1 {
+			diags.AddError("failed", fmt.Sprintf("call %d", i))
+			return false
+		}
+		return true
+	})
+
+	require.Equal(t, int32(4), calls.Load())
+	require.Equal(t, []bool{true, false, true, false}, ok)
+	require.Equal(t, 2, diags.ErrorsCount())
+	require.Equal(t, []string{"a", "c"}, filterByIndex([]string{"a", "b", "c", "d"}, ok))
+}
@@ -3,19 +3,21 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"terraform-provider-i3dnet/internal/one_api"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -36,14 +38,22 @@ type flexvmNodeResource struct {
 }
 
 type FlexvmNodeModel struct {
-	CloudID  types.String   `tfsdk:"cloud_id"`
-	ID       types.String   `tfsdk:"id"`
-	Name     types.String   `tfsdk:"name"`
-	Serial   types.String   `tfsdk:"serial"`
-	Status   types.String   `tfsdk:"status"`
-	Timeouts timeouts.Value `tfsdk:"timeouts"`
+	CloudID          types.String   `tfsdk:"cloud_id"`
+	OnDestroyWithVMs types.String   `tfsdk:"on_destroy_with_vms"`
+	ID               types.String   `tfsdk:"id"`
+	Name             types.String   `tfsdk:"name"`
+	Serial           types.String   `tfsdk:"serial"`
+	Status           types.String   `tfsdk:"status"`
+	Timeouts         timeouts.Value `tfsdk:"timeouts"`
 }
 
+// Values of the on_destroy_with_vms attribute.
+const (
+	flexvmOnDestroyWithVMsFail  = "fail"
+	flexvmOnDestroyWithVMsWait  = "wait"
+	flexvmOnDestroyWithVMsForce = "force"
+)
+
 func (r *flexvmNodeResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
 }
@@ -66,6 +76,15 @@ func (r *flexvmNodeResource) Schema(ctx context.Context, req resource.SchemaRequ
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"on_destroy_with_vms": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "What to do when destroying the Node while VMs are placed on it: `fail` refuses to destroy " +
+					"the Node, `wait` waits until the VMs are gone, e.g. deleted by another resource, and `force` deletes the VMs " +
+					"first. Defaults to `fail`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(flexvmOnDestroyWithVMsFail, flexvmOnDestroyWithVMsWait, flexvmOnDestroyWithVMsForce),
+				},
+			},
 			"id": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "Cloud Node UUID.",
@@ -111,20 +130,13 @@ func (r *flexvmNodeResource) Create(ctx context.Context, req resource.CreateRequ
 
 	cloudID := data.CloudID.ValueString()
 
-	nodeResp, err := r.client.FlexvmCreateNode(ctx, cloudID)
+	node, err := r.client.FlexvmCreateNode(ctx, cloudID)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating FlexVM Cloud Node",
-			fmt.Sprintf("Unexpected error: %v", err),
-		)
-		return
-	}
-	if nodeResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating FlexVM Cloud Node", nodeResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error creating FlexVM Cloud Node", err, &resp.Diagnostics)
 		return
 	}
 
-	flexvmNodeRespToState(nodeResp.Node, &data)
+	flexvmNodeRespToState(node, &data)
 
 	// Save state early to prevent dangling Nodes on timeout.
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -162,8 +174,15 @@ func (r *flexvmNodeResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *flexvmNodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// No update API exists and the only configurable attribute (cloud_id) has
-	// RequiresReplace, so Terraform destroys and recreates instead of calling Update.
+	// No update API exists and cloud_id has RequiresReplace, so only
+	// on_destroy_with_vms and timeouts can change, which live in state only.
+	var data FlexvmNodeModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *flexvmNodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -207,17 +226,15 @@ func (r *flexvmNodeResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	nodeResp, err := r.client.FlexvmDeleteNode(ctx, cloudID, nodeID)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting FlexVM Cloud Node",
-			"Could not delete FlexVM Cloud Node: "+err.Error(),
-		)
+	// The API deletes a Node even when VMs are placed on it, taking them
+	// down; check first, to either refuse or to get the VMs off the Node.
+	if !r.drain(ctx, cloudID, nodeID, data.OnDestroyWithVMs.ValueString(), &resp.Diagnostics) {
 		return
 	}
-	if nodeResp.ErrorResponse != nil {
+
+	if err := r.client.FlexvmDeleteNode(ctx, cloudID, nodeID); err != nil {
 		// Any non-2XX response is treated as a deletion failure.
-		AddErrorResponseToDiags("Error deleting FlexVM Cloud Node", nodeResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error deleting FlexVM Cloud Node", err, &resp.Diagnostics)
 		return
 	}
 
@@ -243,7 +260,7 @@ func (r *flexvmNodeResource) ImportState(ctx context.Context, req resource.Impor
 // becomes terminal. See getNode for more on a terminal state. getNode keeps
 // data in sync with the latest status, so callers should inspect data.Status.
 func (r *flexvmNodeResource) waitForCreated(ctx context.Context, cloudID, nodeID string, state *tfsdk.State, data *FlexvmNodeModel, diags *diag.Diagnostics) {
-	err := r.poll(ctx, 10*time.Second, 30*time.Second, func() bool {
+	err := poll(ctx, 10*time.Second, 30*time.Second, func() bool {
 		terminal, failed := r.getNode(ctx, cloudID, nodeID, true, state, data, diags)
 		if failed {
 			return false
@@ -260,10 +277,135 @@ func (r *flexvmNodeResource) waitForCreated(ctx context.Context, cloudID, nodeID
 	}
 }
 
+// drain makes sure no VMs are placed on the Node before deleting it, as
+// configured by on_destroy_with_vms. It reports whether the Node can be
+// deleted.
+func (r *flexvmNodeResource) drain(ctx context.Context, cloudID, nodeID, onDestroyWithVMs string, diags *diag.Diagnostics) bool {
+	logFields := map[string]any{
+		"cloud_id":            cloudID,
+		"node_id":             nodeID,
+		"on_destroy_with_vms": onDestroyWithVMs,
+	}
+
+	vms, err := placedFlexvmVMs(ctx, r.client, cloudID, nodeID)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexVM VMs", err, diags)
+		return false
+	}
+	if len(vms) == 0 {
+		return true
+	}
+
+	switch onDestroyWithVMs {
+	case flexvmOnDestroyWithVMsWait:
+		tflog.Debug(ctx, "Waiting for VMs to leave FlexVM Cloud Node", logFields)
+
+	case flexvmOnDestroyWithVMsForce:
+		tflog.Warn(ctx, "Deleting the VMs placed on FlexVM Cloud Node", logFields)
+
+		forEachConcurrently(len(vms), diags, func(i int, diags *diag.Diagnostics) bool {
+			if err := deletePlacedFlexvmVM(ctx, r.client, cloudID, vms[i].ID); err != nil {
+				AddErrorResponseToDiags(fmt.Sprintf("Error deleting FlexVM VM %s", vms[i].ID), err, diags)
+				return false
+			}
+			return true
+		})
+		if diags.HasError() {
+			return false
+		}
+
+	default:
+		diags.AddError(
+			"FlexVM Cloud Node still hosts VMs",
+			fmt.Sprintf("Node %s hosts %s. Delete the VMs first, or set on_destroy_with_vms to %q or %q.",
+				nodeID, describeFlexvmVMs(vms), flexvmOnDestroyWithVMsWait, flexvmOnDestroyWithVMsForce),
+		)
+		return false
+	}
+
+	err = poll(ctx, 500*time.Millisecond, 10*time.Second, func() bool {
+		vms, err = placedFlexvmVMs(ctx, r.client, cloudID, nodeID)
+		if err != nil {
+			AddErrorResponseToDiags("Error listing FlexVM VMs", err, diags)
+			return true
+		}
+		return len(vms) == 0
+	})
+	if err != nil {
+		diags.AddError(
+			"Error waiting for VMs to leave FlexVM Cloud Node",
+			fmt.Sprintf("Error: %v\nNode %s still hosts %s.", err, nodeID, describeFlexvmVMs(vms)),
+		)
+		return false
+	}
+
+	return !diags.HasError()
+}
+
+// deletePlacedFlexvmVM starts deleting the VM vmID. Like
+// flexvmVMResource.Delete, it waits for a VM in transition, e.g. still
+// starting, to settle before deleting it, and treats a VM that is already
+// gone or being deleted as deleted.
+func deletePlacedFlexvmVM(ctx context.Context, client *one_api.Client, cloudID, vmID string) error {
+	err := client.FlexvmDeleteVM(ctx, cloudID, vmID)
+	if one_api.IsFlexvmVMInTransition(err) {
+		var lastStatus string
+		reachedTerminal, waitErr := waitForFlexvmStable(ctx, client, cloudID, vmID, &lastStatus)
+		if waitErr != nil {
+			return fmt.Errorf("error waiting for VM to stabilise, last status %q: %w", lastStatus, waitErr)
+		}
+		if reachedTerminal {
+			return nil
+		}
+		err = client.FlexvmDeleteVM(ctx, cloudID, vmID)
+	}
+
+	switch {
+	case err == nil, one_api.IsNotFound(err), one_api.IsConflict(err), one_api.IsFlexvmVMTerminal(err):
+		return nil
+	default:
+		return err
+	}
+}
+
+// placedFlexvmVMs returns the VMs placed on the Node nodeID. Deleted and
+// failed VMs do not count, as they do not prevent deleting the Node.
+func placedFlexvmVMs(ctx context.Context, client *one_api.Client, cloudID, nodeID string) ([]one_api.FlexvmVM, error) {
+	vms, err := client.FlexvmListVMs(ctx, cloudID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(vms, func(vm one_api.FlexvmVM) bool {
+		return !isPlacedFlexvmVM(vm) || vm.Node.ID != nodeID
+	}), nil
+}
+
+// isPlacedFlexvmVM reports whether vm is placed on a Node and holds its
+// resources.
+func isPlacedFlexvmVM(vm one_api.FlexvmVM) bool {
+	return vm.Node != nil && vm.Status != "deleted" && vm.Status != "failed"
+}
+
+// describeFlexvmVMs describes vms for diagnostics, e.g.
+// `2 VMs: "web-1" (<uuid>), "web-2" (<uuid>)`.
+func describeFlexvmVMs(vms []one_api.FlexvmVM) string {
+	names := make([]string, 0, len(vms))
+	for _, vm := range vms {
+		names = append(names, fmt.Sprintf("%q (%s)", vm.Name, vm.ID))
+	}
+
+	noun := "VMs"
+	if len(vms) == 1 {
+		noun = "VM"
+	}
+	return fmt.Sprintf("%d %s: %s", len(vms), noun, strings.Join(names, ", "))
+}
+
 // waitForDeleted polls the Node until it reaches a terminal state. See
 // getNode for more on a terminal state.
 func (r *flexvmNodeResource) waitForDeleted(ctx context.Context, cloudID, nodeID string, state *tfsdk.State, data *FlexvmNodeModel, diags *diag.Diagnostics) {
-	err := r.poll(ctx, 500*time.Millisecond, 10*time.Second, func() bool {
+	err := poll(ctx, 500*time.Millisecond, 10*time.Second, func() bool {
 		terminal, failed := r.getNode(ctx, cloudID, nodeID, false, state, data, diags)
 		if failed {
 			return false
@@ -281,7 +423,7 @@ func (r *flexvmNodeResource) waitForDeleted(ctx context.Context, cloudID, nodeID
 // poll invokes check on an interval until it reports done (or returns an error).
 // The first call happens after initialWait, subsequent calls after interval.
 // The wait is bounded by ctx.
-func (r *flexvmNodeResource) poll(ctx context.Context, initialWait, interval time.Duration, check func() bool) error {
+func poll(ctx context.Context, initialWait, interval time.Duration, check func() bool) error {
 	timer := time.NewTimer(initialWait)
 	defer timer.Stop()
 
@@ -315,47 +457,44 @@ func (r *flexvmNodeResource) poll(ctx context.Context, initialWait, interval tim
 // and the Node is not terminal, the latest Node details are written to data, so
 // callers can inspect data (e.g. data.Status) instead of a returned object.
 func (r *flexvmNodeResource) getNode(ctx context.Context, cloudID, nodeID string, allowFailed bool, state *tfsdk.State, data *FlexvmNodeModel, diags *diag.Diagnostics) (terminal bool, failed bool) {
-	nodeResp, err := r.client.FlexvmGetNode(ctx, cloudID, nodeID)
+	node, terminal, err := fetchNode(ctx, r.client, cloudID, nodeID, allowFailed)
 	if err != nil {
-		diags.AddError(
-			"Error reading FlexVM Cloud Node",
-			"Could not read FlexVM Cloud Node id "+nodeID+": "+err.Error(),
-		)
+		AddErrorResponseToDiags("Error reading FlexVM Cloud Node", err, diags)
 		return false, true
 	}
-
-	if nodeResp.ErrorResponse != nil {
-		if nodeResp.ErrorResponse.StatusCode == http.StatusNotFound {
-			state.RemoveResource(ctx)
-			return true, false
-		}
-		AddErrorResponseToDiags("Error reading FlexVM Cloud Node", nodeResp.ErrorResponse, diags)
-		return false, true
+	if terminal {
+		state.RemoveResource(ctx)
+		return true, false
 	}
 
-	if nodeResp.Node == nil {
-		diags.AddError(
-			"Error reading FlexVM Cloud Node",
-			"call to FlexvmGetNode: no node was set in the response",
-		)
-		return false, true
+	// Not terminal: refresh state with the latest Node details.
+	flexvmNodeRespToState(node, data)
+	diags.Append(state.Set(ctx, data)...)
+	return false, false
+}
+
+// fetchNode fetches the Node and reports whether it is terminal, following
+// the rules of getNode. A terminal Node is returned as nil. Any failure to
+// read the Node, other than a 404, is returned as err.
+func fetchNode(ctx context.Context, client *one_api.Client, cloudID, nodeID string, allowFailed bool) (node *one_api.FlexvmNodeObj, terminal bool, err error) {
+	node, err = client.FlexvmGetNode(ctx, cloudID, nodeID)
+	if err != nil {
+		if one_api.IsNotFound(err) {
+			return nil, true, nil
+		}
+		return nil, false, err
 	}
 
-	switch nodeResp.Node.Status {
+	switch node.Status {
 	case "failed":
 		if !allowFailed {
-			state.RemoveResource(ctx)
-			return true, false
+			return nil, true, nil
 		}
 	case "deleted":
-		state.RemoveResource(ctx)
-		return true, false
+		return nil, true, nil
 	}
 
-	// Not terminal: refresh state with the latest Node details.
-	flexvmNodeRespToState(nodeResp.Node, data)
-	diags.Append(state.Set(ctx, data)...)
-	return false, false
+	return node, false, nil
 }
 
 // flexvmNodeRespToState maps an API Node object onto the resource state model.
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+	"terraform-provider-i3dnet/internal/one_api/fake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeFlexvmVMs(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `1 VM: "web-1" (a)`, describeFlexvmVMs([]one_api.FlexvmVM{{ID: "a", Name: "web-1"}}))
+	require.Equal(t, `2 VMs: "web-1" (a), "web-2" (b)`,
+		describeFlexvmVMs([]one_api.FlexvmVM{{ID: "a", Name: "web-1"}, {ID: "b", Name: "web-2"}}))
+}
+
+func TestIsPlacedFlexvmVM(t *testing.T) {
+	t.Parallel()
+
+	node := &one_api.FlexvmNode{ID: "n"}
+	require.True(t, isPlacedFlexvmVM(one_api.FlexvmVM{Status: "running", Node: node}))
+	require.True(t, isPlacedFlexvmVM(one_api.FlexvmVM{Status: "deleting", Node: node}))
+	require.False(t, isPlacedFlexvmVM(one_api.FlexvmVM{Status: "deleted", Node: node}))
+	require.False(t, isPlacedFlexvmVM(one_api.FlexvmVM{Status: "failed", Node: node}))
+	require.False(t, isPlacedFlexvmVM(one_api.FlexvmVM{Status: "provisioning"}))
+}
+
+func TestDeletePlacedFlexvmVMInTransition(t *testing.T) {
+	t.Parallel()
+
+	const delay = time.Second
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t, fake.WithTransitionDelay(delay)).Client()
+	require.NoError(t, err)
+
+	cloud, err := c.FlexvmCreateCloud(ctx, one_api.FlexvmCloudCreateRequest{Name: "cloud", Site: "frmtl1", InstanceType: "bm7.std.8"})
+	require.NoError(t, err)
+	_, err = c.FlexvmCreateNode(ctx, cloud.ID)
+	require.NoError(t, err)
+	time.Sleep(delay)
+
+	vm, err := c.FlexvmCreateVM(ctx, cloud.ID, one_api.FlexvmCreateVMRequest{
+		Name:             "vm",
+		InstanceTypeName: "vm.4c.8g",
+		ImageName:        "ubuntu-2404-server-amd64",
+	})
+	require.NoError(t, err)
+
+	// The VM is still provisioning, which the API refuses to delete.
+	require.NoError(t, deletePlacedFlexvmVM(ctx, c, cloud.ID, vm.ID))
+
+	vm, err = c.FlexvmGetVM(ctx, cloud.ID, vm.ID)
+	require.NoError(t, err)
+	require.Contains(t, []string{"deleting", "deleted"}, vm.Status)
+}
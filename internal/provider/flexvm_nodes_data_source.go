@@ -3,7 +3,6 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
 
 	"terraform-provider-i3dnet/internal/one_api"
 
@@ -91,29 +90,21 @@ func (d *flexvmNodesDataSource) Read(ctx context.Context, req datasource.ReadReq
 		return
 	}
 
-	nodesResp, err := d.client.FlexvmListNodes(ctx, data.CloudID.ValueString())
+	nodes, err := d.client.FlexvmListNodes(ctx, data.CloudID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing FlexVM Cloud Nodes",
-			"Could not list FlexVM Cloud Nodes for cloud_id "+data.CloudID.ValueString()+": "+err.Error(),
-		)
-		return
-	}
-
-	if nodesResp.ErrorResponse != nil {
-		if nodesResp.ErrorResponse.StatusCode == http.StatusNotFound {
+		if one_api.IsNotFound(err) {
 			resp.Diagnostics.AddError(
 				"FlexVM Cloud not found",
 				fmt.Sprintf("No FlexVM Cloud found for id %s", data.CloudID.ValueString()),
 			)
 			return
 		}
-		AddErrorResponseToDiags("Error listing FlexVM Cloud Nodes", nodesResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error listing FlexVM Cloud Nodes", err, &resp.Diagnostics)
 		return
 	}
 
-	nodeValues := make([]attr.Value, 0, len(nodesResp.Nodes))
-	for _, node := range nodesResp.Nodes {
+	nodeValues := make([]attr.Value, 0, len(nodes))
+	for _, node := range nodes {
 		obj, diags := types.ObjectValue(flexvmNodeObjectAttrTypes, map[string]attr.Value{
 			"id":     types.StringValue(node.ID),
 			"name":   types.StringValue(node.Name),
@@ -22,7 +22,7 @@ func TestAccFlexvmNodesDataSource(t *testing.T) {
 	t.Parallel()
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: providerConfig(t, resourceNsFlexvm) + fmt.Sprintf(`
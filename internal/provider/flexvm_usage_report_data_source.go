@@ -0,0 +1,332 @@
+package provider
+
+import (
+	"context"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource                     = (*flexvmUsageReportDataSource)(nil)
+	_ datasource.DataSourceWithConfigure        = (*flexvmUsageReportDataSource)(nil)
+	_ datasource.DataSourceWithConfigValidators = (*flexvmUsageReportDataSource)(nil)
+)
+
+func NewFlexvmUsageReportDataSource() datasource.DataSource {
+	return &flexvmUsageReportDataSource{}
+}
+
+// flexvmUsageReportDataSource reads the VM usage of the organization over a
+// reporting period, e.g. for chargeback per team tag.
+type flexvmUsageReportDataSource struct {
+	client *one_api.Client
+}
+
+type flexvmUsageReportDataSourceModel struct {
+	Start          types.String `tfsdk:"start"`
+	End            types.String `tfsdk:"end"`
+	PeriodStart    types.String `tfsdk:"period_start"`
+	PeriodEnd      types.String `tfsdk:"period_end"`
+	TotalNodes     types.Int64  `tfsdk:"total_nodes"`
+	TotalActiveVMs types.Int64  `tfsdk:"total_active_vms"`
+	Clouds         types.List   `tfsdk:"clouds"`
+	ByTag          types.List   `tfsdk:"by_tag"`
+	VMs            types.List   `tfsdk:"vms"`
+}
+
+var flexvmUsageReportCloudObjectAttrTypes = map[string]attr.Type{
+	"id":                 types.StringType,
+	"site":               types.StringType,
+	"instance_type":      types.StringType,
+	"nodes":              types.Int64Type,
+	"active_vms":         types.Int64Type,
+	"total_vms_created":  types.Int64Type,
+	"total_vms_released": types.Int64Type,
+	"total_vm_hours":     types.Int64Type,
+}
+
+var flexvmUsageReportTagObjectAttrTypes = map[string]attr.Type{
+	"tag":                types.StringType,
+	"active_vms":         types.Int64Type,
+	"total_vms_created":  types.Int64Type,
+	"total_vms_released": types.Int64Type,
+	"total_vm_hours":     types.Int64Type,
+}
+
+var flexvmUsageReportVMObjectAttrTypes = map[string]attr.Type{
+	"id":                 types.StringType,
+	"name":               types.StringType,
+	"status":             types.StringType,
+	"instance_type_name": types.StringType,
+	"image_name":         types.StringType,
+	"cloud_id":           types.StringType,
+	"site":               types.StringType,
+	"tags":               types.ListType{ElemType: types.StringType},
+	"created_at":         types.StringType,
+	"released_at":        types.StringType,
+	"vm_hours":           types.Int64Type,
+}
+
+func (d *flexvmUsageReportDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexvmUsageReportDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_usage_report"
+}
+
+func (d *flexvmUsageReportDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	usageAttributes := func(attributes map[string]schema.Attribute) map[string]schema.Attribute {
+		attributes["active_vms"] = schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Number of VMs alive when the report was generated.",
+		}
+		attributes["total_vms_created"] = schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Number of VMs created during the period.",
+		}
+		attributes["total_vms_released"] = schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Number of VMs deleted during the period.",
+		}
+		attributes["total_vm_hours"] = schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "VM hours consumed during the period.",
+		}
+		return attributes
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the FlexVM usage of your organization over a reporting period, aggregated by Cloud and " +
+			"by tag, e.g. to charge VM hours back to the teams tagging their VMs.",
+		Attributes: map[string]schema.Attribute{
+			"start": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Start of the reporting period (RFC3339), inclusive. Must be set together with `end`. " +
+					"When neither is set, the period is the current calendar month.",
+			},
+			"end": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "End of the reporting period (RFC3339), exclusive. Must be set together with `start`.",
+			},
+			"period_start": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Start of the reporting period the report covers (RFC3339).",
+			},
+			"period_end": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "End of the reporting period the report covers (RFC3339).",
+			},
+			"total_nodes": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Number of nodes across all Clouds.",
+			},
+			"total_active_vms": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Number of VMs alive across all Clouds.",
+			},
+			"clouds": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Usage by Cloud.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: usageAttributes(map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Cloud UUID.",
+						},
+						"site": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The i3D site (location) in which the Cloud is located.",
+						},
+						"instance_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The FlexMetal instance type shared by every node in the Cloud.",
+						},
+						"nodes": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of nodes in the Cloud.",
+						},
+					}),
+				},
+			},
+			"by_tag": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Usage by VM tag. A VM with several tags counts towards each of them.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: usageAttributes(map[string]schema.Attribute{
+						"tag": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The VM tag.",
+						},
+					}),
+				},
+			},
+			"vms": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Usage by VM, for every VM alive during the period.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "VM UUID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "VM name.",
+						},
+						"status": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The status of the VM when the report was generated.",
+						},
+						"instance_type_name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the instance type the VM is based on.",
+						},
+						"image_name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the image the VM was created from.",
+						},
+						"cloud_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "UUID of the Cloud the VM belongs to.",
+						},
+						"site": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The i3D site (location) of the VM.",
+						},
+						"tags": schema.ListAttribute{
+							ElementType:         types.StringType,
+							Computed:            true,
+							MarkdownDescription: "The tags of the VM.",
+						},
+						"created_at": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "When the VM was created (RFC3339).",
+						},
+						"released_at": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "When the VM was deleted (RFC3339). Null while the VM is alive.",
+						},
+						"vm_hours": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "VM hours consumed during the period.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexvmUsageReportDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.RequiredTogether(
+			path.MatchRoot("start"),
+			path.MatchRoot("end"),
+		),
+	}
+}
+
+func (d *flexvmUsageReportDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexvmUsageReportDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	report, err := d.client.FlexvmGetUsageReport(ctx, data.Start.ValueString(), data.End.ValueString())
+	if err != nil {
+		AddErrorResponseToDiags("Error reading FlexVM usage report", err, &resp.Diagnostics)
+		return
+	}
+
+	resp.Diagnostics.Append(flexvmUsageReportToState(ctx, report, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func flexvmUsageReportToState(ctx context.Context, report *one_api.FlexvmUsageReport, data *flexvmUsageReportDataSourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	usageValues := func(activeVMs int, u one_api.FlexvmUsageReportUsage, values map[string]attr.Value) map[string]attr.Value {
+		values["active_vms"] = types.Int64Value(int64(activeVMs))
+		values["total_vms_created"] = types.Int64Value(int64(u.TotalVMsCreated))
+		values["total_vms_released"] = types.Int64Value(int64(u.TotalVMsReleased))
+		values["total_vm_hours"] = types.Int64Value(int64(u.TotalVMHours))
+		return values
+	}
+
+	cloudValues := make([]attr.Value, 0, len(report.Clouds))
+	for _, c := range report.Clouds {
+		obj, d := types.ObjectValue(flexvmUsageReportCloudObjectAttrTypes, usageValues(c.ActiveVMs, c.PeriodUsage, map[string]attr.Value{
+			"id":            types.StringValue(c.ID),
+			"site":          types.StringValue(c.Site),
+			"instance_type": types.StringValue(c.InstanceType),
+			"nodes":         types.Int64Value(int64(c.Nodes)),
+		}))
+		diags.Append(d...)
+		cloudValues = append(cloudValues, obj)
+	}
+
+	tagValues := make([]attr.Value, 0, len(report.ByTag))
+	for _, t := range report.ByTag {
+		obj, d := types.ObjectValue(flexvmUsageReportTagObjectAttrTypes, usageValues(t.ActiveVMs, t.FlexvmUsageReportUsage, map[string]attr.Value{
+			"tag": types.StringValue(t.Tag),
+		}))
+		diags.Append(d...)
+		tagValues = append(tagValues, obj)
+	}
+
+	vmValues := make([]attr.Value, 0, len(report.VMs))
+	for _, vm := range report.VMs {
+		tags, d := types.ListValueFrom(ctx, types.StringType, append([]string{}, vm.Tags...))
+		diags.Append(d...)
+
+		releasedAt := types.StringNull()
+		if vm.ReleasedAt != "" {
+			releasedAt = types.StringValue(vm.ReleasedAt)
+		}
+
+		obj, d := types.ObjectValue(flexvmUsageReportVMObjectAttrTypes, map[string]attr.Value{
+			"id":                 types.StringValue(vm.VMID),
+			"name":               types.StringValue(vm.Name),
+			"status":             types.StringValue(vm.Status),
+			"instance_type_name": types.StringValue(vm.Plan),
+			"image_name":         types.StringValue(vm.Image),
+			"cloud_id":           types.StringValue(vm.CloudID),
+			"site":               types.StringValue(vm.Site),
+			"tags":               tags,
+			"created_at":         types.StringValue(vm.CreatedAt),
+			"released_at":        releasedAt,
+			"vm_hours":           types.Int64Value(int64(vm.VMHours)),
+		})
+		diags.Append(d...)
+		vmValues = append(vmValues, obj)
+	}
+
+	var d diag.Diagnostics
+	data.PeriodStart = types.StringValue(report.Period.Start)
+	data.PeriodEnd = types.StringValue(report.Period.End)
+	data.TotalNodes = types.Int64Value(int64(report.TotalNodes))
+	data.TotalActiveVMs = types.Int64Value(int64(report.TotalActiveVMs))
+	data.Clouds, d = types.ListValue(types.ObjectType{AttrTypes: flexvmUsageReportCloudObjectAttrTypes}, cloudValues)
+	diags.Append(d...)
+	data.ByTag, d = types.ListValue(types.ObjectType{AttrTypes: flexvmUsageReportTagObjectAttrTypes}, tagValues)
+	diags.Append(d...)
+	data.VMs, d = types.ListValue(types.ObjectType{AttrTypes: flexvmUsageReportVMObjectAttrTypes}, vmValues)
+	diags.Append(d...)
+
+	return diags
+}
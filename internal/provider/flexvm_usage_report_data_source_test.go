@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFlexvmUsageReportDataSource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_usage_report" "test" {
+  start = "2026-01-01T00:00:00Z"
+  end   = "2026-02-01T00:00:00Z"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_usage_report.test", "period_start", "2026-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_usage_report.test", "period_end", "2026-02-01T00:00:00Z"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_usage_report.test", "total_nodes"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_usage_report.test", "total_active_vms"),
+					resource.TestCheckResourceAttrSet("data.i3dnet_flexvm_usage_report.test", "clouds.#"),
+				),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+data "i3dnet_flexvm_usage_report" "test" {
+  start = "2026-01-01T00:00:00Z"
+}
+`,
+				ExpectError: regexp.MustCompile(`These attributes must be configured together`),
+			},
+		},
+	})
+}
@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource              = (*flexvmVMActionResource)(nil)
+	_ resource.ResourceWithConfigure = (*flexvmVMActionResource)(nil)
+)
+
+func NewFlexvmVMActionResource() resource.Resource {
+	return &flexvmVMActionResource{}
+}
+
+// flexvmVMActionResource sends a one-off command, e.g. a reboot, to a FlexVM
+// VM when it is created. Changing any of its arguments, typically triggers,
+// replaces it and so sends the command again. Destroying it does nothing.
+type flexvmVMActionResource struct {
+	client *one_api.Client
+}
+
+type flexvmVMActionModel struct {
+	ID         types.String   `tfsdk:"id"`
+	CloudID    types.String   `tfsdk:"cloud_id"`
+	VMID       types.String   `tfsdk:"vm_id"`
+	Command    types.String   `tfsdk:"command"`
+	Triggers   types.Map      `tfsdk:"triggers"`
+	Status     types.String   `tfsdk:"status"`
+	ExecutedAt types.String   `tfsdk:"executed_at"`
+	Timeouts   timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (r *flexvmVMActionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *flexvmVMActionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_vm_action"
+}
+
+func (r *flexvmVMActionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Sends a one-off `reboot` or `reset` command to a FlexVM VM and waits for the VM to leave " +
+			"and return to `running`. The outcome is reported as a warning.\n\nThe command is sent when the resource is created. Changing any argument, typically " +
+			"`triggers`, replaces the resource and sends the command again. Destroying the resource does not affect " +
+			"the VM. To start or stop a VM, use the `power_state` attribute of `i3dnet_flexvm_vm` instead.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier of the action, in the `cloud_id/vm_id` format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"cloud_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "UUID of the cloud of the VM.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vm_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "UUID of the VM to send the command to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"command": schema.StringAttribute{
+				Required: true,
+				MarkdownDescription: "The command to send: `reboot` for a graceful reboot, or `reset` for a hard " +
+					"reset. The VM must be running.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(one_api.FlexvmCommandReboot, one_api.FlexvmCommandReset),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				MarkdownDescription: "Arbitrary values that send the command again when they change, e.g. " +
+					"`{ config = sha256(local.config) }`.",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"status": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The status of the VM once the command completed.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"executed_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the command was sent.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
+	}
+}
+
+func (r *flexvmVMActionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data flexvmVMActionModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	cloudID := data.CloudID.ValueString()
+	vmID := data.VMID.ValueString()
+	command := data.Command.ValueString()
+	var lastStatus string
+
+	// Wait for a VM that is still starting or stopping, as the API rejects
+	// commands for VMs in transition.
+	reachedTerminal, err := waitForFlexvmStable(ctx, r.client, cloudID, vmID, &lastStatus)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error waiting for FlexvmVM to be ready for a command",
+			fmt.Sprintf("Error: %v\nLast status: %s\nVM id: %s", err, lastStatus, vmID),
+		)
+		return
+	}
+	if reachedTerminal || lastStatus != flexvmPowerStateRunning {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("Cannot %s FlexvmVM", command),
+			fmt.Sprintf("The VM must be running to %s it, but it is %q.\nVM id: %s", command, lastStatus, vmID),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Sending FlexvmVM command", map[string]any{"cloud_id": cloudID, "vm_id": vmID, "command": command})
+
+	executedAt := time.Now()
+	if err := r.client.FlexvmExecuteCommand(ctx, cloudID, vmID, command); err != nil {
+		AddErrorResponseToDiags(fmt.Sprintf("Error sending %s command to FlexvmVM", command), err, &resp.Diagnostics)
+		return
+	}
+
+	observed, err := waitForFlexvmVMCommand(ctx, r.client, cloudID, vmID, &lastStatus)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("FlexvmVM %s command did not complete", command),
+			fmt.Sprintf("The %s command was accepted, but the VM did not return to running.\nError: %v\nLast status: %s\nVM id: %s",
+				command, err, lastStatus, vmID),
+		)
+		return
+	}
+
+	elapsed := time.Since(executedAt).Round(time.Second)
+	tflog.Info(ctx, "FlexvmVM command completed", map[string]any{
+		"cloud_id": cloudID, "vm_id": vmID, "command": command, "observed": observed, "elapsed": elapsed.String(),
+	})
+
+	if observed {
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("FlexvmVM %s command completed", command),
+			fmt.Sprintf("The VM left and returned to %s after the %s command.\nFinal status: %s\nElapsed: %s\nVM id: %s",
+				flexvmPowerStateRunning, command, lastStatus, elapsed, vmID),
+		)
+	} else {
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("FlexvmVM %s command completion not observed", command),
+			fmt.Sprintf("The %s command was accepted, but the VM was %s at every poll during %s, so the command either "+
+				"completed between two polls or had no effect.\nFinal status: %s\nElapsed: %s\nVM id: %s",
+				command, flexvmPowerStateRunning, flexvmCommandTransitionWindow, lastStatus, elapsed, vmID),
+		)
+	}
+
+	data.ID = types.StringValue(cloudID + "/" + vmID)
+	data.Status = types.StringValue(lastStatus)
+	data.ExecutedAt = types.StringValue(executedAt.UTC().Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read only checks that the VM still exists: the action itself has no state
+// in the API.
+func (r *flexvmVMActionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data flexvmVMActionModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.FlexvmGetVM(ctx, data.CloudID.ValueString(), data.VMID.ValueString())
+	if err != nil {
+		if one_api.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		AddErrorResponseToDiags("Error reading FlexvmVM", err, &resp.Diagnostics)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only stores changed timeouts: every other argument forces a
+// replacement.
+func (r *flexvmVMActionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data flexvmVMActionModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the action from the state; a command cannot be undone.
+func (r *flexvmVMActionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// flexvmCommandTransitionWindow is how long to wait for a VM to leave running
+// after a command before assuming that the command completed between polls.
+const flexvmCommandTransitionWindow = time.Minute
+
+// waitForFlexvmVMCommand waits for the VM to leave running after a command and
+// then to return to it, so that the first status accepted is not the one
+// from before the command. It reports whether the VM was seen leaving running:
+// a quick reboot may complete between two polls, in which case it returns once
+// flexvmCommandTransitionWindow has passed with the VM still running.
+func waitForFlexvmVMCommand(ctx context.Context, client *one_api.Client, cloudID, vmID string, lastStatus *string) (bool, error) {
+	until := func(running bool) func(vm *one_api.FlexvmVM, err error) (bool, error) {
+		return func(vm *one_api.FlexvmVM, err error) (bool, error) {
+			if err != nil {
+				return false, err
+			}
+			*lastStatus = vm.Status
+			if *lastStatus == "failed" {
+				return false, errors.New("VM reached 'failed' status")
+			}
+			return (*lastStatus == flexvmPowerStateRunning) == running, nil
+		}
+	}
+
+	transitionCtx, cancel := context.WithTimeout(ctx, flexvmCommandTransitionWindow)
+	defer cancel()
+
+	err := waitForFlexvmVMCondition(transitionCtx, client, cloudID, vmID, time.Second, 2*time.Second, until(false))
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, waitForFlexvmVMCondition(ctx, client, cloudID, vmID, 5*time.Second, 5*time.Second, until(true))
+}
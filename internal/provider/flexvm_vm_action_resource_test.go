@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"terraform-provider-i3dnet/internal/one_api"
+	"terraform-provider-i3dnet/internal/one_api/fake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFlexvmVMActionResource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	config := func(configHash string) string {
+		return providerConfig(t, resourceNsFlexvm) + fmt.Sprintf(`
+resource "i3dnet_flexvm_vm" "test" {
+  cloud_id           = "019d24e2-98fa-701a-8475-8ac0ff1f4a4a"
+  name               = "terraform-gh-workflows-action-test"
+  instance_type_name = "vm.4c.8g"
+  image_name         = "ubuntu-2404-server-amd64"
+  ssh_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHwdgjY0AlmkeLknBpoVmJg/quNSifyBHEK1MREpV4Ri john.doe@i3d.net"]
+}
+
+resource "i3dnet_flexvm_vm_action" "reboot" {
+  cloud_id = i3dnet_flexvm_vm.test.cloud_id
+  vm_id    = i3dnet_flexvm_vm.test.id
+  command  = "reboot"
+  triggers = {
+    config = "%s"
+  }
+}
+`, configHash)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config("v1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm_action.reboot", "status", "running"),
+					resource.TestCheckResourceAttrSet("i3dnet_flexvm_vm_action.reboot", "executed_at"),
+					resource.TestCheckResourceAttrPair("i3dnet_flexvm_vm_action.reboot", "vm_id", "i3dnet_flexvm_vm.test", "id"),
+				),
+			},
+			// Changing the triggers reboots the VM again, without replacing it.
+			{
+				Config: config("v2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("i3dnet_flexvm_vm_action.reboot", plancheck.ResourceActionReplace),
+						plancheck.ExpectResourceAction("i3dnet_flexvm_vm.test", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm_action.reboot", "status", "running"),
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm_action.reboot", "triggers.config", "v2"),
+				),
+			},
+		},
+	})
+}
+
+func TestWaitForFlexvmVMCommand(t *testing.T) {
+	t.Parallel()
+
+	// Longer than the first poll, so that the reboot is seen.
+	const delay = 1500 * time.Millisecond
+
+	ctx := context.Background()
+	c, err := fake.NewServer(t, fake.WithTransitionDelay(delay)).Client()
+	require.NoError(t, err)
+
+	cloud, err := c.FlexvmCreateCloud(ctx, one_api.FlexvmCloudCreateRequest{Name: "cloud", Site: "frmtl1", InstanceType: "bm7.std.8"})
+	require.NoError(t, err)
+	_, err = c.FlexvmCreateNode(ctx, cloud.ID)
+	require.NoError(t, err)
+	time.Sleep(delay)
+
+	vm, err := c.FlexvmCreateVM(ctx, cloud.ID, one_api.FlexvmCreateVMRequest{
+		Name:             "vm",
+		InstanceTypeName: "vm.4c.8g",
+		ImageName:        "ubuntu-2404-server-amd64",
+	})
+	require.NoError(t, err)
+	time.Sleep(delay)
+
+	require.NoError(t, c.FlexvmExecuteCommand(ctx, cloud.ID, vm.ID, one_api.FlexvmCommandReboot))
+
+	var lastStatus string
+	observed, err := waitForFlexvmVMCommand(ctx, c, cloud.ID, vm.ID, &lastStatus)
+	require.NoError(t, err)
+	require.True(t, observed)
+	require.Equal(t, flexvmPowerStateRunning, lastStatus)
+}
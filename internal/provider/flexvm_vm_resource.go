@@ -5,7 +5,6 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,6 +34,7 @@ var (
 	_ resource.ResourceWithConfigure        = (*flexvmVMResource)(nil)
 	_ resource.ResourceWithImportState      = (*flexvmVMResource)(nil)
 	_ resource.ResourceWithConfigValidators = (*flexvmVMResource)(nil)
+	_ resource.ResourceWithModifyPlan       = (*flexvmVMResource)(nil)
 )
 
 // flexvmUserDataMaxLen is the maximum length (in characters) the API accepts
@@ -42,12 +42,20 @@ var (
 // the base64-encoded string.
 const flexvmUserDataMaxLen = 64000
 
+// Values of the power_state attribute. They match the VM statuses the API
+// reports once a start or stop command completes.
+const (
+	flexvmPowerStateRunning = "running"
+	flexvmPowerStateStopped = "stopped"
+)
+
 func NewFlexvmVMResource() resource.Resource {
 	return &flexvmVMResource{}
 }
 
 type flexvmVMResource struct {
-	client *one_api.Client
+	client  *one_api.Client
+	catalog *catalog
 }
 
 type FlexvmVMModel struct {
@@ -61,6 +69,7 @@ type FlexvmVMModel struct {
 	Tags             types.List     `tfsdk:"tags"`
 	ID               types.String   `tfsdk:"id"`
 	Status           types.String   `tfsdk:"status"`
+	PowerState       types.String   `tfsdk:"power_state"`
 	IPs              types.List     `tfsdk:"ips"`
 	InstanceType     types.Object   `tfsdk:"instance_type"`
 	Image            types.Object   `tfsdk:"image"`
@@ -106,7 +115,9 @@ var nodeObjectAttrTypes = map[string]attr.Type{
 }
 
 func (r *flexvmVMResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	r.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+	if data := fromProviderData(req.ProviderData, &resp.Diagnostics); data != nil {
+		r.client, r.catalog = data.client, data.catalog
+	}
 }
 
 func (r *flexvmVMResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -139,15 +150,18 @@ func (r *flexvmVMResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"instance_type_name": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "The instance type name to base the VM on.",
+				Required: true,
+				MarkdownDescription: "The instance type name to base the VM on. It is checked against the available instance types when planning, " +
+					"see the `i3dnet_flexvm_instance_types` data source. A warning is also shown when the Cloud has no room left for the VMs " +
+					"of this instance type created by the plan, see the `i3dnet_flexvm_capacity` data source.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"image_name": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "The image name to create the VM from.",
+				Required: true,
+				MarkdownDescription: "The image name to create the VM from. It is checked against the available images when planning, see the " +
+					"`i3dnet_flexvm_images` data source.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -205,6 +219,19 @@ func (r *flexvmVMResource) Schema(ctx context.Context, req resource.SchemaReques
 				Computed:            true,
 				MarkdownDescription: "The status of the VM.",
 			},
+			"power_state": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "The desired power state of the VM, `running` or `stopped`. Changing it starts or " +
+					"stops the VM in place, without replacing it. When not set, the VM is left running after creation " +
+					"and the attribute reports the power state of the VM.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(flexvmPowerStateRunning, flexvmPowerStateStopped),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"ips": schema.ListNestedAttribute{
 				Computed:            true,
 				MarkdownDescription: "A list of IP address objects that belong to the VM.",
@@ -323,6 +350,7 @@ func (r *flexvmVMResource) Schema(ctx context.Context, req resource.SchemaReques
 			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create: true,
+				Update: true,
 				Delete: true,
 			}),
 		},
@@ -338,6 +366,27 @@ func (r *flexvmVMResource) ConfigValidators(ctx context.Context) []resource.Conf
 	}
 }
 
+// ModifyPlan checks image_name and instance_type_name against the FlexVM
+// catalogs, so that a typo fails the plan rather than the apply. It then
+// checks that the Cloud has room for the planned VMs. This is not done in
+// ValidateConfig, as the provider is not always configured yet when
+// validating.
+func (r *flexvmVMResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	validatePlannedCatalogName(ctx, req, path.Root("image_name"), &r.catalog.flexvmImages, "FlexVM image",
+		"Use the i3dnet_flexvm_images data source to list the available images.", &resp.Diagnostics)
+	validatePlannedCatalogName(ctx, req, path.Root("instance_type_name"), &r.catalog.flexvmInstanceTypes, "FlexVM instance type",
+		"Use the i3dnet_flexvm_instance_types data source to list the available instance types.", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkFlexvmVMCapacity(ctx, r.catalog, req, &resp.Diagnostics)
+}
+
 func (r *flexvmVMResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data FlexvmVMModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -390,20 +439,13 @@ func (r *flexvmVMResource) Create(ctx context.Context, req resource.CreateReques
 	ctx, cancel := context.WithTimeout(ctx, createTimeout)
 	defer cancel()
 
-	vmResp, err := r.client.FlexvmCreateVM(ctx, data.CloudID.ValueString(), createReq)
+	vm, err := r.client.FlexvmCreateVM(ctx, data.CloudID.ValueString(), createReq)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating FlexvmVM",
-			fmt.Sprintf("Unexpected error: %v", err),
-		)
-		return
-	}
-	if vmResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating FlexvmVM", vmResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error creating FlexvmVM", err, &resp.Diagnostics)
 		return
 	}
 
-	flexvmVMRespToState(vmResp.VM, &data)
+	flexvmVMRespToState(vm, &data)
 
 	// Save state early to prevent dangling VMs on timeout
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -416,15 +458,12 @@ func (r *flexvmVMResource) Create(ctx context.Context, req resource.CreateReques
 	lastStatus := data.Status.ValueString()
 	var lastVM *one_api.FlexvmVM
 
-	err = r.waitForCondition(ctx, cloudID, vmID, 10*time.Second, 5*time.Second, func(vmResp *one_api.FlexvmVMResponse) (bool, error) {
-		if vmResp.ErrorResponse != nil {
-			return false, fmt.Errorf("call to FlexvmGetVM error response: %s", vmResp.ErrorResponse.ErrorMessage)
-		}
-		if vmResp.VM == nil {
-			return false, errors.New("call to FlexvmGetVM: no vm was set in the response")
+	err = waitForFlexvmVMCondition(ctx, r.client, cloudID, vmID, 10*time.Second, 5*time.Second, func(vm *one_api.FlexvmVM, err error) (bool, error) {
+		if err != nil {
+			return false, err
 		}
-		lastVM = vmResp.VM
-		lastStatus = vmResp.VM.Status
+		lastVM = vm
+		lastStatus = vm.Status
 		return lastStatus == "running" || lastStatus == "failed", nil
 	})
 	if err != nil {
@@ -446,18 +485,24 @@ func (r *flexvmVMResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	// VM is running, get final details
-	getResp, err := r.client.FlexvmGetVM(ctx, cloudID, vmID)
-	if err != nil {
-		resp.Diagnostics.AddError("Error getting FlexvmVM", "Unexpected error: "+err.Error())
-		return
+	if data.PowerState.ValueString() == flexvmPowerStateStopped {
+		if err := r.changePowerState(ctx, cloudID, vmID, flexvmPowerStateStopped, &lastStatus); err != nil {
+			resp.Diagnostics.AddError(
+				"Error stopping FlexvmVM",
+				fmt.Sprintf("Error: %v\nLast status: %s\nVM id: %s", err, lastStatus, vmID),
+			)
+			return
+		}
 	}
-	if getResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error getting FlexvmVM", getResp.ErrorResponse, &resp.Diagnostics)
+
+	// VM is in its desired power state, get final details
+	vm, err = r.client.FlexvmGetVM(ctx, cloudID, vmID)
+	if err != nil {
+		AddErrorResponseToDiags("Error getting FlexvmVM", err, &resp.Diagnostics)
 		return
 	}
 
-	flexvmVMRespToState(getResp.VM, &data)
+	flexvmVMRespToState(vm, &data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -468,31 +513,100 @@ func (r *flexvmVMResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
-	vmResp, err := r.client.FlexvmGetVM(ctx, data.CloudID.ValueString(), data.ID.ValueString())
+	vm, err := r.client.FlexvmGetVM(ctx, data.CloudID.ValueString(), data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading FlexvmVM",
-			"Could not read FlexvmVM id "+data.ID.ValueString()+": "+err.Error(),
-		)
-		return
-	}
-
-	if vmResp.ErrorResponse != nil {
-		if vmResp.ErrorResponse.StatusCode == http.StatusNotFound {
+		if one_api.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		AddErrorResponseToDiags("Error reading FlexvmVM", vmResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error reading FlexvmVM", err, &resp.Diagnostics)
 		return
 	}
 
-	flexvmVMRespToState(vmResp.VM, &data)
+	flexvmVMRespToState(vm, &data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// Update only changes the power state of the VM: every other configurable
+// attribute has RequiresReplace, as the API has no VM update endpoint.
 func (r *flexvmVMResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// No update API exists. All mutable attributes have RequiresReplace,
-	// so Terraform will destroy and recreate instead of calling Update.
+	var plan, state FlexvmVMModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	cloudID := state.CloudID.ValueString()
+	vmID := state.ID.ValueString()
+	lastStatus := state.Status.ValueString()
+
+	if target := plan.PowerState; !target.IsUnknown() && !target.IsNull() && !target.Equal(state.PowerState) {
+		if err := r.changePowerState(ctx, cloudID, vmID, target.ValueString(), &lastStatus); err != nil {
+			resp.Diagnostics.AddError(
+				"Error changing FlexvmVM power state",
+				fmt.Sprintf("Error: %v\nTarget power state: %s\nLast status: %s\nVM id: %s", err, target.ValueString(), lastStatus, vmID),
+			)
+			return
+		}
+	}
+
+	vm, err := r.client.FlexvmGetVM(ctx, cloudID, vmID)
+	if err != nil {
+		AddErrorResponseToDiags("Error getting FlexvmVM", err, &resp.Diagnostics)
+		return
+	}
+
+	flexvmVMRespToState(vm, &plan)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// changePowerState brings the VM to the target power state ("running" or
+// "stopped"). It waits for a VM in transition to settle, sends the start or
+// stop command when the VM is not in the target state yet, and waits for the
+// VM to reach it.
+func (r *flexvmVMResource) changePowerState(ctx context.Context, cloudID, vmID, target string, lastStatus *string) error {
+	reachedTerminal, err := waitForFlexvmStable(ctx, r.client, cloudID, vmID, lastStatus)
+	if err != nil {
+		return err
+	}
+	if reachedTerminal {
+		return fmt.Errorf("VM is %s and can no longer be started or stopped", *lastStatus)
+	}
+	if *lastStatus == target {
+		return nil
+	}
+
+	command := one_api.FlexvmCommandStart
+	if target == flexvmPowerStateStopped {
+		command = one_api.FlexvmCommandStop
+	}
+
+	tflog.Debug(ctx, "Sending FlexvmVM power command", map[string]any{"cloud_id": cloudID, "vm_id": vmID, "command": command})
+
+	if err := r.client.FlexvmExecuteCommand(ctx, cloudID, vmID, command); err != nil {
+		return err
+	}
+
+	return waitForFlexvmVMCondition(ctx, r.client, cloudID, vmID, 2*time.Second, 5*time.Second, func(vm *one_api.FlexvmVM, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		*lastStatus = vm.Status
+		if *lastStatus == "failed" {
+			return false, errors.New("VM reached 'failed' status")
+		}
+		return *lastStatus == target, nil
+	})
 }
 
 func (r *flexvmVMResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -515,34 +629,24 @@ func (r *flexvmVMResource) Delete(ctx context.Context, req resource.DeleteReques
 	vmID := data.ID.ValueString()
 	lastStatus := data.Status.ValueString()
 
-	vmResp, err := r.client.FlexvmDeleteVM(ctx, cloudID, vmID)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting FlexvmVM",
-			"Could not delete FlexvmVM: "+err.Error(),
-		)
-		return
-	}
-
 	logFields := map[string]any{"cloud_id": cloudID, "vm_id": vmID}
 
-	if vmResp.ErrorResponse != nil {
-		errResp := vmResp.ErrorResponse
+	if err := r.client.FlexvmDeleteVM(ctx, cloudID, vmID); err != nil {
 		switch {
-		case errResp.StatusCode == http.StatusConflict:
+		case one_api.IsConflict(err):
 			// VM is already being deleted; poll until it reaches "deleted" (or 404).
 			tflog.Debug(ctx, "FlexvmVM is already deleting; polling for the 'deleted' state", logFields)
 
-		case errResp.StatusCode == http.StatusUnprocessableEntity && errResp.ErrorCode == one_api.FlexvmErrCodeVMTerminal:
+		case one_api.IsFlexvmVMTerminal(err):
 			// VM is already in a terminal state ("failed" or "deleted"); treat as deleted.
 			tflog.Debug(ctx, "FlexvmVM is already in a terminal state; treating it as deleted", logFields)
 			return
 
-		case errResp.StatusCode == http.StatusUnprocessableEntity && errResp.ErrorCode == one_api.FlexvmErrCodeVMInTransition:
+		case one_api.IsFlexvmVMInTransition(err):
 			// VM is in a transitional state; wait until it stabilizes, then retry delete.
 			tflog.Debug(ctx, "FlexvmVM is in a transitional state; polling for a stable state before retrying delete", logFields)
 
-			reachedTerminal, err := r.waitForFlexvmStable(ctx, cloudID, vmID, &lastStatus)
+			reachedTerminal, err := waitForFlexvmStable(ctx, r.client, cloudID, vmID, &lastStatus)
 			if err != nil {
 				resp.Diagnostics.AddError(
 					"FlexvmVM deletion failed",
@@ -555,26 +659,17 @@ func (r *flexvmVMResource) Delete(ctx context.Context, req resource.DeleteReques
 				return
 			}
 
-			retryResp, err := r.client.FlexvmDeleteVM(ctx, cloudID, vmID)
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error deleting FlexvmVM",
-					"Could not delete FlexvmVM on retry: "+err.Error(),
-				)
-				return
-			}
-			if retryResp.ErrorResponse != nil {
-				retryErr := retryResp.ErrorResponse
-				if retryErr.StatusCode == http.StatusUnprocessableEntity && retryErr.ErrorCode == one_api.FlexvmErrCodeVMTerminal {
+			if err := r.client.FlexvmDeleteVM(ctx, cloudID, vmID); err != nil {
+				if one_api.IsFlexvmVMTerminal(err) {
 					tflog.Debug(ctx, "FlexvmVM became terminal between stabilisation and retry; treating as deleted", logFields)
 					return
 				}
-				AddErrorResponseToDiags("Error deleting FlexvmVM on retry", retryErr, &resp.Diagnostics)
+				AddErrorResponseToDiags("Error deleting FlexvmVM on retry", err, &resp.Diagnostics)
 				return
 			}
 
 		default:
-			AddErrorResponseToDiags("Error deleting FlexvmVM", errResp, &resp.Diagnostics)
+			AddErrorResponseToDiags("Error deleting FlexvmVM", err, &resp.Diagnostics)
 			return
 		}
 	}
@@ -593,39 +688,33 @@ func (r *flexvmVMResource) Delete(ctx context.Context, req resource.DeleteReques
 // deleted from "failed" when the API tells us so explicitly via the
 // FlexvmErrCodeVMTerminal error on the DELETE call.
 func (r *flexvmVMResource) waitForFlexvmDeleted(ctx context.Context, cloudID, vmID string, lastStatus *string) error {
-	return r.waitForCondition(ctx, cloudID, vmID, 500*time.Millisecond, 5*time.Second, func(vmResp *one_api.FlexvmVMResponse) (bool, error) {
-		if vmResp.ErrorResponse != nil {
-			if vmResp.ErrorResponse.StatusCode == http.StatusNotFound {
+	return waitForFlexvmVMCondition(ctx, r.client, cloudID, vmID, 500*time.Millisecond, 5*time.Second, func(vm *one_api.FlexvmVM, err error) (bool, error) {
+		if err != nil {
+			if one_api.IsNotFound(err) {
 				return true, nil
 			}
-			return false, fmt.Errorf("call to FlexvmGetVM error response: %s", vmResp.ErrorResponse.ErrorMessage)
+			return false, err
 		}
-		if vmResp.VM == nil {
-			return false, errors.New("call to FlexvmGetVM: no vm was set in the response")
-		}
-		*lastStatus = vmResp.VM.Status
+		*lastStatus = vm.Status
 		return *lastStatus == "deleted", nil
 	})
 }
 
-// waitForFlexvmStable polls the VM until it reaches a state in which delete can
-// be retried ("running" or "stopped") or a terminal state ("failed", "deleted",
+// waitForFlexvmStable polls the VM until it reaches a stable state in which it
+// accepts a delete or power command ("running" or "stopped") or a terminal state ("failed", "deleted",
 // or 404). It returns reachedTerminal=true when the VM is already gone or has
-// failed, so the caller can skip the retry-delete step.
-func (r *flexvmVMResource) waitForFlexvmStable(ctx context.Context, cloudID, vmID string, lastStatus *string) (bool, error) {
+// failed, so the caller can skip e.g. the retry-delete step.
+func waitForFlexvmStable(ctx context.Context, client *one_api.Client, cloudID, vmID string, lastStatus *string) (bool, error) {
 	var reachedTerminal bool
-	err := r.waitForCondition(ctx, cloudID, vmID, 500*time.Millisecond, 5*time.Second, func(vmResp *one_api.FlexvmVMResponse) (bool, error) {
-		if vmResp.ErrorResponse != nil {
-			if vmResp.ErrorResponse.StatusCode == http.StatusNotFound {
+	err := waitForFlexvmVMCondition(ctx, client, cloudID, vmID, 500*time.Millisecond, 5*time.Second, func(vm *one_api.FlexvmVM, err error) (bool, error) {
+		if err != nil {
+			if one_api.IsNotFound(err) {
 				reachedTerminal = true
 				return true, nil
 			}
-			return false, fmt.Errorf("call to FlexvmGetVM error response: %s", vmResp.ErrorResponse.ErrorMessage)
-		}
-		if vmResp.VM == nil {
-			return false, errors.New("call to FlexvmGetVM: no vm was set in the response")
+			return false, err
 		}
-		*lastStatus = vmResp.VM.Status
+		*lastStatus = vm.Status
 		switch *lastStatus {
 		case "running", "stopped":
 			return true, nil
@@ -652,8 +741,11 @@ func (r *flexvmVMResource) ImportState(ctx context.Context, req resource.ImportS
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[1])...)
 }
 
-func (r *flexvmVMResource) waitForCondition(ctx context.Context, cloudID, vmID string,
-	initialPollInterval, pollInterval time.Duration, check func(vmResp *one_api.FlexvmVMResponse) (bool, error)) error {
+// waitForFlexvmVMCondition polls the VM until check reports done or returns an error.
+// check receives the result of every FlexvmGetVM call, including API errors,
+// so it can decide how to handle e.g. a 404.
+func waitForFlexvmVMCondition(ctx context.Context, client *one_api.Client, cloudID, vmID string,
+	initialPollInterval, pollInterval time.Duration, check func(vm *one_api.FlexvmVM, err error) (bool, error)) error {
 	timer := time.NewTimer(initialPollInterval)
 	defer timer.Stop()
 
@@ -662,12 +754,12 @@ func (r *flexvmVMResource) waitForCondition(ctx context.Context, cloudID, vmID s
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-timer.C:
-			vmResp, err := r.client.FlexvmGetVM(ctx, cloudID, vmID)
+			vm, err := client.FlexvmGetVM(ctx, cloudID, vmID)
 			if err != nil {
-				return fmt.Errorf("call to FlexvmGetVM: %w", err)
+				err = fmt.Errorf("call to FlexvmGetVM: %w", err)
 			}
 
-			done, err := check(vmResp)
+			done, err := check(vm, err)
 			if err != nil {
 				return err
 			}
@@ -733,6 +825,15 @@ func flexvmVMRespToState(vm *one_api.FlexvmVM, data *FlexvmVMModel) {
 	data.Name = types.StringValue(vm.Name)
 	data.Description = types.StringValue(vm.Description)
 	data.Status = types.StringValue(vm.Status)
+	switch vm.Status {
+	case flexvmPowerStateRunning, flexvmPowerStateStopped:
+		data.PowerState = types.StringValue(vm.Status)
+	default:
+		// A VM in transition keeps its last known power state.
+		if data.PowerState.IsUnknown() {
+			data.PowerState = types.StringNull()
+		}
+	}
 	data.CreatedAt = types.StringValue(vm.CreatedAt)
 	data.DeletedAt = types.StringValue(vm.DeletedAt)
 
@@ -1,10 +1,13 @@
 package provider
 
 import (
+	"fmt"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
@@ -17,7 +20,7 @@ func TestAccFlexvmVMResource(t *testing.T) {
 	t.Parallel()
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -79,7 +82,7 @@ func TestAccFlexvmVMResourceUserData(t *testing.T) {
 	t.Parallel()
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing, using user_data_file instead of ssh_keys.
 			{
@@ -123,3 +126,92 @@ resource "i3dnet_flexvm_vm" "test" {
 		},
 	})
 }
+
+func TestAccFlexvmVMResourcePowerState(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	config := func(powerState string) string {
+		return providerConfig(t, resourceNsFlexvm) + fmt.Sprintf(`
+resource "i3dnet_flexvm_vm" "test" {
+  cloud_id           = "019d24e2-98fa-701a-8475-8ac0ff1f4a4a"
+  name               = "terraform-gh-workflows-power-test"
+  instance_type_name = "vm.4c.8g"
+  image_name         = "ubuntu-2404-server-amd64"
+  ssh_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHwdgjY0AlmkeLknBpoVmJg/quNSifyBHEK1MREpV4Ri john.doe@i3d.net"]
+  power_state        = "%s"
+}
+`, powerState)
+	}
+
+	var vmID string
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			// Create the VM stopped.
+			{
+				Config: config("stopped"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm.test", "power_state", "stopped"),
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm.test", "status", "stopped"),
+					resource.TestCheckResourceAttrWith("i3dnet_flexvm_vm.test", "id", func(value string) error {
+						vmID = value
+						return nil
+					}),
+				),
+			},
+			// Start it in place.
+			{
+				Config: config("running"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("i3dnet_flexvm_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm.test", "power_state", "running"),
+					resource.TestCheckResourceAttr("i3dnet_flexvm_vm.test", "status", "running"),
+					resource.TestCheckResourceAttrWith("i3dnet_flexvm_vm.test", "id", func(value string) error {
+						if value != vmID {
+							return fmt.Errorf("VM was replaced: id changed from %s to %s", vmID, value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccFlexvmVMResourceInvalidNames(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+resource "i3dnet_flexvm_vm" "test" {
+  cloud_id           = "019d24e2-98fa-701a-8475-8ac0ff1f4a4a"
+  name               = "terraform-gh-workflows-invalid-names-test"
+  instance_type_name = "vm.4c.8gb"
+  image_name         = "ubuntu-2404-server-amd"
+  ssh_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHwdgjY0AlmkeLknBpoVmJg/quNSifyBHEK1MREpV4Ri john.doe@i3d.net"]
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`(?s)Did\s+you\s+mean\s+"ubuntu-2404-server-amd64"\?.*Did\s+you\s+mean\s+"vm.4c.8g"\?`),
+			},
+		},
+	})
+}
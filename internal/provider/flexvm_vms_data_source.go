@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"context"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*flexvmVMsDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*flexvmVMsDataSource)(nil)
+)
+
+// flexvmVMStatuses are the statuses a FlexVM VM can have.
+var flexvmVMStatuses = []string{
+	"provisioning", "created", "starting", "running", "stopping", "stopped", "paused", "failed", "deleting", "deleted",
+}
+
+func NewFlexvmVMsDataSource() datasource.DataSource {
+	return &flexvmVMsDataSource{}
+}
+
+// flexvmVMsDataSource lists the VMs within a FlexVM Cloud, including VMs that
+// are not managed by Terraform.
+type flexvmVMsDataSource struct {
+	client *one_api.Client
+}
+
+type flexvmVMsDataSourceModel struct {
+	CloudID types.String `tfsdk:"cloud_id"`
+	Status  types.String `tfsdk:"status"`
+	VMs     types.List   `tfsdk:"vms"`
+}
+
+var flexvmVMObjectAttrTypes = map[string]attr.Type{
+	"id":                 types.StringType,
+	"name":               types.StringType,
+	"description":        types.StringType,
+	"status":             types.StringType,
+	"instance_type_name": types.StringType,
+	"image_name":         types.StringType,
+	"ips":                types.ListType{ElemType: types.ObjectType{AttrTypes: ipsObjectAttrTypes}},
+	"node":               types.ObjectType{AttrTypes: nodeObjectAttrTypes},
+	"tags":               types.ListType{ElemType: types.StringType},
+	"created_at":         types.StringType,
+}
+
+func (d *flexvmVMsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFromProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *flexvmVMsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flexvm_vms"
+}
+
+func (d *flexvmVMsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the VMs within an i3D.net FlexVM Cloud, including VMs that are not managed by this " +
+			"Terraform configuration, e.g. to register them in a load balancer or DNS.",
+		Attributes: map[string]schema.Attribute{
+			"cloud_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "UUID of the Cloud whose VMs to list.",
+			},
+			"status": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include VMs with this status, e.g. `running`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(flexvmVMStatuses...),
+				},
+			},
+			"vms": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The VMs within the Cloud.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "VM UUID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "VM name.",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Free-form description of the VM.",
+						},
+						"status": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The status of the VM.",
+						},
+						"instance_type_name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the instance type the VM is based on.",
+						},
+						"image_name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the image the VM was created from.",
+						},
+						"ips": schema.ListNestedAttribute{
+							Computed:            true,
+							MarkdownDescription: "The IP addresses of the VM.",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"address": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "An IP address, can be v4 or v6, public or private.",
+									},
+									"public": schema.BoolAttribute{
+										Computed:            true,
+										MarkdownDescription: "Whether the IP address is a public one.",
+									},
+								},
+							},
+						},
+						"node": schema.SingleNestedAttribute{
+							Computed:            true,
+							MarkdownDescription: "The node the VM is placed on. Null while the VM is not placed yet.",
+							Attributes: map[string]schema.Attribute{
+								"id": schema.StringAttribute{
+									Computed:            true,
+									MarkdownDescription: "Cloud Node UUID.",
+								},
+								"name": schema.StringAttribute{
+									Computed:            true,
+									MarkdownDescription: "Cloud Node name.",
+								},
+								"instance_type": schema.StringAttribute{
+									Computed:            true,
+									MarkdownDescription: "Cloud Node FlexMetal instance type.",
+								},
+								"serial": schema.StringAttribute{
+									Computed:            true,
+									MarkdownDescription: "Cloud Node serial number.",
+								},
+							},
+						},
+						"tags": schema.ListAttribute{
+							ElementType:         types.StringType,
+							Computed:            true,
+							MarkdownDescription: "The tags the VM was created with.",
+						},
+						"created_at": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "When the VM was created (RFC3339).",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *flexvmVMsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data flexvmVMsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vms, err := d.client.FlexvmListVMs(ctx, data.CloudID.ValueString(), data.Status.ValueString())
+	if err != nil {
+		AddErrorResponseToDiags("Error listing FlexVM VMs", err, &resp.Diagnostics)
+		return
+	}
+
+	vmValues := make([]attr.Value, 0, len(vms))
+	for _, vm := range vms {
+		ipValues := make([]attr.Value, 0, len(vm.IPs))
+		for _, ip := range vm.IPs {
+			ipObj, diags := types.ObjectValue(ipsObjectAttrTypes, map[string]attr.Value{
+				"address": types.StringValue(ip.Address),
+				"public":  types.BoolValue(ip.Public),
+			})
+			resp.Diagnostics.Append(diags...)
+			ipValues = append(ipValues, ipObj)
+		}
+		ips, diags := types.ListValue(types.ObjectType{AttrTypes: ipsObjectAttrTypes}, ipValues)
+		resp.Diagnostics.Append(diags...)
+
+		node := types.ObjectNull(nodeObjectAttrTypes)
+		if vm.Node != nil {
+			node, diags = types.ObjectValue(nodeObjectAttrTypes, map[string]attr.Value{
+				"id":            types.StringValue(vm.Node.ID),
+				"name":          types.StringValue(vm.Node.Name),
+				"instance_type": types.StringValue(vm.Node.InstanceType),
+				"serial":        types.StringValue(vm.Node.Serial),
+			})
+			resp.Diagnostics.Append(diags...)
+		}
+
+		tags, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, vm.Tags...))
+		resp.Diagnostics.Append(diags...)
+
+		obj, diags := types.ObjectValue(flexvmVMObjectAttrTypes, map[string]attr.Value{
+			"id":                 types.StringValue(vm.ID),
+			"name":               types.StringValue(vm.Name),
+			"description":        types.StringValue(vm.Description),
+			"status":             types.StringValue(vm.Status),
+			"instance_type_name": types.StringValue(vm.InstanceType.Name),
+			"image_name":         types.StringValue(vm.Image.Name),
+			"ips":                ips,
+			"node":               node,
+			"tags":               tags,
+			"created_at":         types.StringValue(vm.CreatedAt),
+		})
+		resp.Diagnostics.Append(diags...)
+		vmValues = append(vmValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vmsList, diags := types.ListValue(types.ObjectType{AttrTypes: flexvmVMObjectAttrTypes}, vmValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.VMs = vmsList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
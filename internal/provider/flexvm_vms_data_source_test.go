@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFlexvmVMsDataSource(t *testing.T) {
+	isMain := os.Getenv("TF_MAIN") == "true"
+	if !isMain {
+		t.Skip("To run this test, set TF_MAIN=true env var")
+	}
+
+	t.Parallel()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexvm) + `
+resource "i3dnet_flexvm_vm" "test" {
+  cloud_id           = "019d24e2-98fa-701a-8475-8ac0ff1f4a4a"
+  name               = "terraform-gh-workflows-vms-data-source-test"
+  instance_type_name = "vm.4c.8g"
+  image_name         = "ubuntu-2404-server-amd64"
+  ssh_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHwdgjY0AlmkeLknBpoVmJg/quNSifyBHEK1MREpV4Ri john.doe@i3d.net"]
+  tags               = ["env:acceptance"]
+}
+
+data "i3dnet_flexvm_vms" "test" {
+  cloud_id = i3dnet_flexvm_vm.test.cloud_id
+  status   = "running"
+
+  depends_on = [i3dnet_flexvm_vm.test]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.i3dnet_flexvm_vms.test", "status", "running"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.i3dnet_flexvm_vms.test", "vms.*", map[string]string{
+						"name":               "terraform-gh-workflows-vms-data-source-test",
+						"status":             "running",
+						"instance_type_name": "vm.4c.8g",
+						"image_name":         "ubuntu-2404-server-amd64",
+						"tags.#":             "1",
+						"tags.0":             "env:acceptance",
+					}),
+				),
+			},
+		},
+	})
+}
@@ -101,9 +101,7 @@ func (d *locationsDataSource) Read(ctx context.Context, req datasource.ReadReque
 	// Read API call logic
 	locations, err := d.client.ListLocations(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Unable to Read i3D.net locations",
-			err.Error(),
-		)
+		AddErrorResponseToDiags("Unable to Read i3D.net locations", err, &resp.Diagnostics)
 		return
 	}
 
@@ -16,7 +16,7 @@ func TestAccLocationDataSource(t *testing.T) {
 	require.NoError(t, err)
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: providerConfig(t, resourceNsFlexmetal) + `
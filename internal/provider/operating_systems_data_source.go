@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*operatingSystemsDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*operatingSystemsDataSource)(nil)
+)
+
+func NewOperatingSystemsDataSource() datasource.DataSource {
+	return &operatingSystemsDataSource{}
+}
+
+// operatingSystemsDataSource lists the operating systems FlexMetal servers
+// can be installed with, optionally filtered and narrowed down to the most
+// recent one.
+type operatingSystemsDataSource struct {
+	catalog *catalog
+}
+
+type operatingSystemsDataSourceModel struct {
+	Family             types.String `tfsdk:"family"`
+	SlugRegex          types.String `tfsdk:"slug_regex"`
+	OSType             types.String `tfsdk:"os_type"`
+	IncludeUnavailable types.Bool   `tfsdk:"include_unavailable"`
+	MostRecent         types.Bool   `tfsdk:"most_recent"`
+	OperatingSystems   types.List   `tfsdk:"operating_systems"`
+}
+
+var operatingSystemObjectAttrTypes = map[string]attr.Type{
+	"id":                      types.Int64Type,
+	"name":                    types.StringType,
+	"slug":                    types.StringType,
+	"os_type":                 types.StringType,
+	"is_odp":                  types.BoolType,
+	"available_for_flexmetal": types.BoolType,
+	"active":                  types.BoolType,
+}
+
+func (d *operatingSystemsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if data := fromProviderData(req.ProviderData, &resp.Diagnostics); data != nil {
+		d.catalog = data.catalog
+	}
+}
+
+func (d *operatingSystemsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operating_systems"
+}
+
+func (d *operatingSystemsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Get the operating systems FlexMetal servers can be installed with. Use the filters to find a " +
+			"valid `os.slug` for `i3dnet_flexmetal_server` without hard-coding it.",
+		Attributes: map[string]schema.Attribute{
+			"family": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Only include operating systems of this family, i.e. whose slug is the family followed " +
+					"by a version, e.g. `ubuntu` or `talos-omni`.",
+			},
+			"slug_regex": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Only include operating systems whose slug matches this regular expression. The API " +
+					"has no separate version field, so use this to select versions, e.g. `^ubuntu-24`.",
+			},
+			"os_type": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only include operating systems of this type: `linux` or `windows`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("linux", "windows"),
+				},
+			},
+			"include_unavailable": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Also include inactive operating systems and operating systems that are not available " +
+					"for FlexMetal, which cannot be used by `i3dnet_flexmetal_server`.",
+			},
+			"most_recent": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Only return the most recent of the matching operating systems, which is the one with " +
+					"the highest version in its slug, e.g. `talos-omni-1123` over `talos-omni-190`. Combine it with `family` " +
+					"to compare versions of the same operating system. An error is returned when no operating system matches.",
+			},
+			"operating_systems": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The matching operating systems, sorted by slug.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Operating system ID.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Operating system name.",
+						},
+						"slug": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Operating system slug, to use as `os.slug` of `i3dnet_flexmetal_server`.",
+						},
+						"os_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The OS type. Can be \"linux\" or \"windows\".",
+						},
+						"is_odp": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the operating system can be used with ODP.",
+						},
+						"available_for_flexmetal": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the operating system can be used with FlexMetal.",
+						},
+						"active": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the operating system is active.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *operatingSystemsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data operatingSystemsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := operatingSystemFilter{
+		family:             data.Family.ValueString(),
+		osType:             data.OSType.ValueString(),
+		includeUnavailable: data.IncludeUnavailable.ValueBool(),
+		mostRecent:         data.MostRecent.ValueBool(),
+	}
+	if !data.SlugRegex.IsNull() && !data.SlugRegex.IsUnknown() {
+		re, err := regexp.Compile(data.SlugRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("slug_regex"), "Invalid slug_regex", err.Error())
+			return
+		}
+		filter.slugRegex = re
+	}
+
+	operatingSystems, err := d.catalog.operatingSystems.get(ctx)
+	if err != nil {
+		AddErrorResponseToDiags("Error listing operating systems", err, &resp.Diagnostics)
+		return
+	}
+
+	operatingSystems = filter.apply(operatingSystems)
+	if filter.mostRecent && len(operatingSystems) == 0 {
+		resp.Diagnostics.AddError(
+			"No operating system found",
+			"No operating system matches the filters. Remove most_recent to get an empty list instead.",
+		)
+		return
+	}
+
+	osValues := make([]attr.Value, 0, len(operatingSystems))
+	for _, os := range operatingSystems {
+		obj, diags := types.ObjectValue(operatingSystemObjectAttrTypes, map[string]attr.Value{
+			"id":                      types.Int64Value(int64(os.ID)),
+			"name":                    types.StringValue(os.Name),
+			"slug":                    types.StringValue(os.Slug),
+			"os_type":                 types.StringValue(osType(os)),
+			"is_odp":                  types.BoolValue(os.IsOdp == 1),
+			"available_for_flexmetal": types.BoolValue(os.AvailableForFlexMetal == 1),
+			"active":                  types.BoolValue(os.Active == 1),
+		})
+		resp.Diagnostics.Append(diags...)
+		osValues = append(osValues, obj)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	osList, diags := types.ListValue(types.ObjectType{AttrTypes: operatingSystemObjectAttrTypes}, osValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.OperatingSystems = osList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// osType returns the type of os, "linux" or "windows", as used by the
+// os_type attributes of data sources.
+func osType(os one_api.OperatingSystem) string {
+	if os.OsGroup == one_api.OsGroupWindows {
+		return "windows"
+	}
+	return "linux"
+}
+
+// operatingSystemFilter selects operating systems by the filters of the
+// operating systems data source.
+type operatingSystemFilter struct {
+	family             string
+	slugRegex          *regexp.Regexp
+	osType             string
+	includeUnavailable bool
+	mostRecent         bool
+}
+
+// apply returns the operating systems matching f, sorted by slug, or only the
+// most recent one when f.mostRecent is set.
+func (f operatingSystemFilter) apply(operatingSystems []one_api.OperatingSystem) []one_api.OperatingSystem {
+	matched := slices.DeleteFunc(slices.Clone(operatingSystems), func(os one_api.OperatingSystem) bool {
+		switch {
+		case !f.includeUnavailable && !os.UsableForFlexMetal():
+			return true
+		case f.family != "" && !isVersionOf(os.Slug, f.family):
+			return true
+		case f.slugRegex != nil && !f.slugRegex.MatchString(os.Slug):
+			return true
+		case f.osType != "" && osType(os) != f.osType:
+			return true
+		}
+		return false
+	})
+
+	slices.SortFunc(matched, func(a, b one_api.OperatingSystem) int {
+		return compareNatural(a.Slug, b.Slug)
+	})
+
+	if f.mostRecent && len(matched) > 0 {
+		return matched[len(matched)-1:]
+	}
+
+	return matched
+}
+
+// isVersionOf reports whether slug is family followed by a version, e.g.
+// "talos-omni-190" of "talos-omni", but not "talos-omni-190" of "talos".
+func isVersionOf(slug, family string) bool {
+	version, ok := strings.CutPrefix(slug, family+"-")
+	return ok && version != "" && version[0] >= '0' && version[0] <= '9'
+}
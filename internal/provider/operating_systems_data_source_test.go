@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"terraform-provider-i3dnet/internal/one_api"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccOperatingSystemsDataSource(t *testing.T) {
+	apiclient := newOneAPIClient(t, resourceNsFlexmetal)
+
+	operatingSystems, err := apiclient.ListOperatingSystems(context.Background())
+	require.NoError(t, err)
+
+	usable := 0
+	for _, os := range operatingSystems {
+		if os.UsableForFlexMetal() {
+			usable++
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_operating_systems" "flexmetal" {}
+
+data "i3dnet_operating_systems" "all" {
+  include_unavailable = true
+}
+
+data "i3dnet_operating_systems" "ubuntu" {
+  family      = "ubuntu"
+  most_recent = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Operating systems from data source should match operating systems from One API
+					resource.TestCheckResourceAttr("data.i3dnet_operating_systems.flexmetal", "operating_systems.#", strconv.Itoa(usable)),
+					resource.TestCheckResourceAttr("data.i3dnet_operating_systems.all", "operating_systems.#", strconv.Itoa(len(operatingSystems))),
+					resource.TestCheckResourceAttr("data.i3dnet_operating_systems.ubuntu", "operating_systems.#", "1"),
+					resource.TestMatchResourceAttr("data.i3dnet_operating_systems.ubuntu", "operating_systems.0.slug", regexp.MustCompile(`^ubuntu-\d`)),
+				),
+			},
+			{
+				Config: providerConfig(t, resourceNsFlexmetal) + `
+data "i3dnet_operating_systems" "none" {
+  family      = "terraform-acceptance-no-such-family"
+  most_recent = true
+}
+`,
+				ExpectError: regexp.MustCompile(`No operating system found`),
+			},
+		},
+	})
+}
+
+func TestOperatingSystemFilter(t *testing.T) {
+	t.Parallel()
+
+	operatingSystems := []one_api.OperatingSystem{
+		{Slug: "ubuntu-2404-lts", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+		{Slug: "ubuntu-2004-lts", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 0},
+		{Slug: "ubuntu-2204-lts", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+		{Slug: "windows-2022", OsGroup: one_api.OsGroupWindows, AvailableForFlexMetal: 1, Active: 1},
+		{Slug: "windows-2019", OsGroup: one_api.OsGroupWindows, AvailableForFlexMetal: 0, Active: 1},
+		{Slug: "talos-omni-1123", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+		{Slug: "talos-omni-190", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+		{Slug: "custom-ipxe", OsGroup: one_api.OsGroupLinux, AvailableForFlexMetal: 1, Active: 1},
+	}
+
+	tests := []struct {
+		name   string
+		filter operatingSystemFilter
+		want   []string
+	}{
+		{
+			name:   "no filter sorts by slug and skips unavailable",
+			filter: operatingSystemFilter{},
+			want:   []string{"custom-ipxe", "talos-omni-190", "talos-omni-1123", "ubuntu-2204-lts", "ubuntu-2404-lts", "windows-2022"},
+		},
+		{
+			name:   "include unavailable",
+			filter: operatingSystemFilter{osType: "windows", includeUnavailable: true},
+			want:   []string{"windows-2019", "windows-2022"},
+		},
+		{
+			name:   "family",
+			filter: operatingSystemFilter{family: "talos-omni"},
+			want:   []string{"talos-omni-190", "talos-omni-1123"},
+		},
+		{
+			name:   "family must be followed by a version",
+			filter: operatingSystemFilter{family: "talos"},
+			want:   []string{},
+		},
+		{
+			name:   "most recent",
+			filter: operatingSystemFilter{family: "talos-omni", mostRecent: true},
+			want:   []string{"talos-omni-1123"},
+		},
+		{
+			name:   "slug regex",
+			filter: operatingSystemFilter{slugRegex: regexp.MustCompile(`^ubuntu-2[02]`), includeUnavailable: true},
+			want:   []string{"ubuntu-2004-lts", "ubuntu-2204-lts"},
+		},
+		{
+			name:   "most recent without match",
+			filter: operatingSystemFilter{family: "debian", mostRecent: true},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			slugs := []string{}
+			for _, os := range tt.filter.apply(operatingSystems) {
+				slugs = append(slugs, os.Slug)
+			}
+			require.Equal(t, tt.want, slugs)
+		})
+	}
+}
@@ -4,25 +4,37 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"terraform-provider-i3dnet/internal/one_api"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ provider.Provider = (*i3dnetProvider)(nil)
+var _ provider.ProviderWithEphemeralResources = (*i3dnetProvider)(nil)
 
 func New() provider.Provider {
 	return &i3dnetProvider{}
 }
 
-type i3dnetProvider struct{}
+type i3dnetProvider struct {
+	// extraClientOptions are appended to the options derived from the
+	// provider configuration, e.g. by tests recording API exchanges.
+	extraClientOptions []one_api.Option
+}
 
 func (p *i3dnetProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -38,14 +50,78 @@ func (p *i3dnetProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				MarkdownDescription: "API base URL. By default it's using `https://api.i3d.net` API URL",
 				Optional:            true, // optional. if not specified it will use the prod api URL
 			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum number of times a One API request is retried after a transient failure "+
+					"(a connection error or a `429`, `502`, `503` or `504` response). Only requests that are safe to repeat are retried. "+
+					"Set to `0` to disable retries. Defaults to `%d`.", one_api.DefaultRetryConfig.MaxRetries),
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"max_retry_wait": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum number of seconds to wait between two retries. The wait grows "+
+					"exponentially (with jitter) up to this value. A `Retry-After` header sent by the API is honoured up to this "+
+					"value or %d seconds, whichever is longer; a request asked to wait longer fails instead. "+
+					"Defaults to `%d`.", int64(one_api.MaxRetryAfter/time.Second), int64(one_api.DefaultRetryConfig.MaxWait/time.Second)),
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"max_requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "Maximum number of One API requests per second sent by the provider. The limit is " +
+					"shared by all resources and data sources, including the loops polling for the status of servers, VMs " +
+					"and nodes, and protects the API key from being throttled on large applies. When the API still " +
+					"throttles the key, all requests back off together. Unlimited by default.",
+				Optional: true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"max_idle_connections": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Size of the pool of keep-alive connections to the One API, shared by all "+
+					"resources and data sources of the provider. Defaults to `%d`.", one_api.DefaultTransportConfig.MaxIdleConns),
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "URL of the proxy to send One API requests through, e.g. `http://proxy.example.com:3128`. " +
+					"By default the `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables are used.",
+				Optional: true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM encoded CA certificate bundle to trust in addition to the system " +
+					"certificates, e.g. when a TLS-intercepting proxy sits between Terraform and the One API.",
+				Optional: true,
+			},
+			"request_timeout": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Timeout in seconds of a single One API request, including reading the "+
+					"response. Retries get a fresh timeout. Defaults to `%d`.", int64(one_api.DefaultTransportConfig.RequestTimeout/time.Second)),
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
 		},
 	}
 }
 
 // i3dnetProviderModel maps provider schema data to a Go type.
 type i3dnetProviderModel struct {
-	APIKey  types.String `tfsdk:"api_key"`
-	BaseURL types.String `tfsdk:"base_url"`
+	APIKey       types.String `tfsdk:"api_key"`
+	BaseURL      types.String `tfsdk:"base_url"`
+	MaxRetries   types.Int64  `tfsdk:"max_retries"`
+	MaxRetryWait types.Int64  `tfsdk:"max_retry_wait"`
+
+	MaxRequestsPerSecond types.Float64 `tfsdk:"max_requests_per_second"`
+
+	MaxIdleConnections types.Int64  `tfsdk:"max_idle_connections"`
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	RequestTimeout     types.Int64  `tfsdk:"request_timeout"`
 }
 
 const (
@@ -72,6 +148,8 @@ func (p *i3dnetProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		)
 	}
 
+	checkClientSettingsKnown(config, &resp.Diagnostics)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -99,16 +177,78 @@ func (p *i3dnetProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		return
 	}
 
-	client, err := one_api.NewClient(apiKey, config.BaseURL.ValueString())
+	client, err := one_api.NewClient(apiKey, config.BaseURL.ValueString(), append(clientOptions(config), p.extraClientOptions...)...)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Could not initialize i3D.net API client",
 			fmt.Sprintf("error: %s", err))
+		return
 	}
 
-	// Make the API client available during DataSource and Resource type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	// Make the API client and catalog available during DataSource, Resource and EphemeralResource type Configure methods.
+	data := newProviderData(client)
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.EphemeralResourceData = data
+}
+
+// checkClientSettingsKnown adds an error for every optional client setting
+// with an unknown value: read as zero, it would e.g. disable retries or the
+// request timeout instead of using the default.
+func checkClientSettingsKnown(config i3dnetProviderModel, diags *diag.Diagnostics) {
+	settings := []struct {
+		name  string
+		value attr.Value
+	}{
+		{"max_retries", config.MaxRetries},
+		{"max_retry_wait", config.MaxRetryWait},
+		{"max_requests_per_second", config.MaxRequestsPerSecond},
+		{"max_idle_connections", config.MaxIdleConnections},
+		{"proxy_url", config.ProxyURL},
+		{"ca_cert_file", config.CACertFile},
+		{"request_timeout", config.RequestTimeout},
+	}
+
+	for _, setting := range settings {
+		if !setting.value.IsUnknown() {
+			continue
+		}
+
+		diags.AddAttributeError(
+			path.Root(setting.name),
+			fmt.Sprintf("Unknown %s", setting.name),
+			fmt.Sprintf("The provider cannot create the i3Dnet API client as there is an unknown configuration value for the %s. ", setting.name)+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+}
+
+// clientOptions translates the optional provider settings into one_api.Client options.
+func clientOptions(config i3dnetProviderModel) []one_api.Option {
+	retry := one_api.DefaultRetryConfig
+	if !config.MaxRetries.IsNull() {
+		retry.MaxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.MaxRetryWait.IsNull() {
+		retry.MaxWait = time.Duration(config.MaxRetryWait.ValueInt64()) * time.Second
+		retry.MinWait = min(retry.MinWait, retry.MaxWait)
+	}
+
+	transport := one_api.DefaultTransportConfig
+	if !config.MaxIdleConnections.IsNull() {
+		transport.MaxIdleConns = int(config.MaxIdleConnections.ValueInt64())
+	}
+	if !config.RequestTimeout.IsNull() {
+		transport.RequestTimeout = time.Duration(config.RequestTimeout.ValueInt64()) * time.Second
+	}
+	transport.ProxyURL = config.ProxyURL.ValueString()
+	transport.CACertFile = config.CACertFile.ValueString()
+
+	return []one_api.Option{
+		one_api.WithRetry(retry),
+		one_api.WithTransport(transport),
+		one_api.WithRateLimit(config.MaxRequestsPerSecond.ValueFloat64()),
+	}
 }
 
 func (p *i3dnetProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -120,9 +260,21 @@ func (p *i3dnetProvider) DataSources(ctx context.Context) []func() datasource.Da
 		NewSshKeyDataSource,
 		NewTagsDataSource,
 		NewLocationsDataSource,
+		NewOperatingSystemsDataSource,
+		NewFlexmetalInstanceTypesDataSource,
+		NewFlexmetalServerDataSource,
+		NewFlexmetalServersDataSource,
+		NewFlexmetalQuotaUsageDataSource,
+		NewFlexmetalCapacityCommitsDataSource,
 		NewFlexvmCloudDataSource,
+		NewFlexvmCloudsDataSource,
 		NewFlexvmNodeDataSource,
 		NewFlexvmNodesDataSource,
+		NewFlexvmVMsDataSource,
+		NewFlexvmImagesDataSource,
+		NewFlexvmInstanceTypesDataSource,
+		NewFlexvmCapacityDataSource,
+		NewFlexvmUsageReportDataSource,
 	}
 }
 
@@ -134,5 +286,13 @@ func (p *i3dnetProvider) Resources(ctx context.Context) []func() resource.Resour
 		NewFlexvmVMResource,
 		NewFlexvmCloudResource,
 		NewFlexvmNodeResource,
+		NewFlexvmNodePoolResource,
+		NewFlexvmVMActionResource,
+	}
+}
+
+func (p *i3dnetProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewServerRootPasswordEphemeralResource,
 	}
 }
@@ -1,13 +1,21 @@
 package provider
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
 	"testing"
 
 	"terraform-provider-i3dnet/internal/one_api"
+	"terraform-provider-i3dnet/internal/one_api/fake"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/stretchr/testify/require"
 )
@@ -17,15 +25,17 @@ const (
 	resourceNsFlexvm    = "flexvm"
 )
 
-var (
-	// testAccProtoV6ProviderFactories are used to instantiate a provider during
-	// acceptance testing. The factory function will be invoked for every Terraform
-	// CLI command executed to create a provider server to which the CLI can
-	// reattach.
-	testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-		"i3dnet": providerserver.NewProtocol6WithError(New()),
+// testAccProtoV6ProviderFactories are used to instantiate a provider during
+// acceptance testing. The factory function will be invoked for every Terraform
+// CLI command executed to create a provider server to which the CLI can
+// reattach.
+func testAccProtoV6ProviderFactories(t *testing.T) map[string]func() (tfprotov6.ProviderServer, error) {
+	t.Helper()
+
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"i3dnet": providerserver.NewProtocol6WithError(&i3dnetProvider{extraClientOptions: testClientOptions(t)}),
 	}
-)
+}
 
 // providerConfig is a shared configuration to combine with the actual
 // test configuration so the i3D.net One API client is properly configured.
@@ -51,7 +61,7 @@ func newOneAPIClient(t *testing.T, resourceNs string) *one_api.Client {
 
 	apiKey, baseURL := oneAPIVars(t, resourceNs)
 
-	client, err := one_api.NewClient(apiKey, baseURL)
+	client, err := one_api.NewClient(apiKey, baseURL, testClientOptions(t)...)
 	require.NoError(t, err)
 
 	return client
@@ -65,6 +75,16 @@ func oneAPIVars(t *testing.T, resourceNs string) (apiKey, baseURL string) {
 			resourceNsFlexmetal, resourceNsFlexvm, resourceNs)
 	}
 
+	if useFakeAPI() {
+		return fake.APIKey, fakeAPI(t).URL()
+	}
+
+	if cassetteMode() == one_api.CassetteReplay {
+		// Replayed requests never reach the API, and the recorded ones do not
+		// hold the API key.
+		return "replayed", one_api.DefaultBaseURL
+	}
+
 	apiKeyEnv := "I3D_FLEXMETAL_API_KEY"
 	if resourceNs == resourceNsFlexvm {
 		apiKeyEnv = "I3D_FLEXVM_API_KEY"
@@ -82,3 +102,110 @@ func oneAPIVars(t *testing.T, resourceNs string) (apiKey, baseURL string) {
 
 	return apiKey, baseURL
 }
+
+// fakeAPIs holds the fake One API server of every running test, so that the
+// provider and the test's own client talk to the same server.
+var fakeAPIs sync.Map // map[*testing.T]*fake.Server
+
+// useFakeAPI reports whether the tests run against the in-memory fake One API
+// instead of a real environment, which is enabled by setting I3D_FAKE_API.
+func useFakeAPI() bool {
+	v, _ := strconv.ParseBool(os.Getenv("I3D_FAKE_API"))
+	return v
+}
+
+// fakeAPI returns the fake One API server of t, starting it on first use.
+func fakeAPI(t *testing.T) *fake.Server {
+	t.Helper()
+
+	if s, ok := fakeAPIs.Load(t); ok {
+		return s.(*fake.Server)
+	}
+
+	s := fake.NewServer(t)
+	fakeAPIs.Store(t, s)
+	t.Cleanup(func() { fakeAPIs.Delete(t) })
+
+	return s
+}
+
+// cassettes holds the cassette of every running test, shared by the clients of
+// the provider and the test's own client.
+var cassettes sync.Map // map[*testing.T]*one_api.Cassette
+
+// cassetteMode returns the mode set with I3D_CASSETTE, "record" or "replay",
+// or zero when API exchanges are neither recorded nor replayed.
+func cassetteMode() one_api.CassetteMode {
+	switch os.Getenv("I3D_CASSETTE") {
+	case "record":
+		return one_api.CassetteRecord
+	case "replay":
+		return one_api.CassetteReplay
+	default:
+		return 0
+	}
+}
+
+var cassetteNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// cassettePath returns the cassette file of t.
+func cassettePath(t *testing.T) string {
+	return filepath.Join("testdata", "cassettes", cassetteNameReplacer.ReplaceAllString(t.Name(), "_")+".json")
+}
+
+// testClientOptions returns the one_api.Client options of t: with I3D_CASSETTE
+// set, the API exchanges of the test are recorded to or replayed from its
+// cassette. A test without a cassette fails in replay mode, so that a missing
+// recording never passes unnoticed, and a recording is only saved when the
+// test passes.
+func testClientOptions(t *testing.T) []one_api.Option {
+	t.Helper()
+
+	mode := cassetteMode()
+	if mode == 0 {
+		return nil
+	}
+
+	if c, ok := cassettes.Load(t); ok {
+		return []one_api.Option{one_api.WithCassette(c.(*one_api.Cassette))}
+	}
+
+	path := cassettePath(t)
+	cassette, err := one_api.NewCassette(path, mode)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("no cassette recorded at %s, record it with I3D_CASSETTE=record", path)
+	}
+	require.NoError(t, err)
+
+	cassettes.Store(t, cassette)
+	t.Cleanup(func() {
+		cassettes.Delete(t)
+		if t.Failed() {
+			t.Logf("test failed, not saving cassette %s", path)
+			return
+		}
+		require.NoError(t, cassette.Save())
+	})
+
+	return []one_api.Option{one_api.WithCassette(cassette)}
+}
+
+func TestCheckClientSettingsKnown(t *testing.T) {
+	config := i3dnetProviderModel{
+		MaxRetries:         types.Int64Unknown(),
+		MaxRetryWait:       types.Int64Value(30),
+		MaxIdleConnections: types.Int64Value(10),
+		ProxyURL:           types.StringNull(),
+		CACertFile:         types.StringUnknown(),
+		RequestTimeout:     types.Int64Unknown(),
+	}
+
+	var diags diag.Diagnostics
+	checkClientSettingsKnown(config, &diags)
+
+	var paths []string
+	for _, d := range diags.Errors() {
+		paths = append(paths, d.(diag.DiagnosticWithPath).Path().String())
+	}
+	require.Equal(t, []string{"max_retries", "ca_cert_file", "request_timeout"}, paths)
+}
@@ -15,7 +15,7 @@ func TestAccSSHKeyDataSource(t *testing.T) {
 	createTestSSHKey(t)
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			// Read testing
 			{
@@ -54,7 +54,7 @@ func createTestSSHKey(t *testing.T) {
 	}
 
 	t.Cleanup(func() {
-		err = apiclient.DeleteSSHKey(context.Background(), response.SSHKey.Uuid)
+		err = apiclient.DeleteSSHKey(context.Background(), response.Uuid)
 		if err != nil {
 			t.Fatalf("error deleting SSH Key: %s", err)
 		}
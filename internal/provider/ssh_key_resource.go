@@ -87,23 +87,18 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// Create new SSH key
-	sshResp, err := r.client.CreateSSHKey(ctx, reqBody)
+	sshKey, err := r.client.CreateSSHKey(ctx, reqBody)
 	if err != nil {
-		resp.Diagnostics.AddError("Error creating ssh key", "Unexpected error: "+err.Error())
-		return
-	}
-
-	if sshResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating ssh key", sshResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error creating ssh key", err, &resp.Diagnostics)
 		return
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	plan.PublicKey = types.StringValue(sshResp.SSHKey.PublicKey)
-	plan.Name = types.StringValue(sshResp.SSHKey.Name)
-	plan.CreatedAt = types.Int64Value(sshResp.SSHKey.CreatedAt)
-	plan.Uuid = types.StringValue(sshResp.SSHKey.Uuid)
-	plan.ID = types.StringValue(sshResp.SSHKey.Uuid)
+	plan.PublicKey = types.StringValue(sshKey.PublicKey)
+	plan.Name = types.StringValue(sshKey.Name)
+	plan.CreatedAt = types.Int64Value(sshKey.CreatedAt)
+	plan.Uuid = types.StringValue(sshKey.Uuid)
+	plan.ID = types.StringValue(sshKey.Uuid)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -124,25 +119,17 @@ func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 
 	// Read API call logic
-	sshResp, err := r.client.GetSSHKey(ctx, data.Uuid.ValueString())
+	sshKey, err := r.client.GetSSHKey(ctx, data.Uuid.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading ssh key",
-			"Could not read ssh key id "+data.Uuid.ValueString()+": "+err.Error(),
-		)
-		return
-	}
-
-	if sshResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error reading ssh key", sshResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error reading ssh key", err, &resp.Diagnostics)
 		return
 	}
 
-	data.PublicKey = types.StringValue(sshResp.SSHKey.PublicKey)
-	data.Name = types.StringValue(sshResp.SSHKey.Name)
-	data.CreatedAt = types.Int64Value(sshResp.SSHKey.CreatedAt)
-	data.Uuid = types.StringValue(sshResp.SSHKey.Uuid)
-	data.ID = types.StringValue(sshResp.SSHKey.Uuid)
+	data.PublicKey = types.StringValue(sshKey.PublicKey)
+	data.Name = types.StringValue(sshKey.Name)
+	data.CreatedAt = types.Int64Value(sshKey.CreatedAt)
+	data.Uuid = types.StringValue(sshKey.Uuid)
+	data.ID = types.StringValue(sshKey.Uuid)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -167,10 +154,7 @@ func (r *sshKeyResource) Update(ctx context.Context, req resource.UpdateRequest,
 	// Delete old key
 	err := r.client.DeleteSSHKey(ctx, state.ID.String())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Could not replace SSHKey",
-			"Unexpected error: "+err.Error(),
-		)
+		AddErrorResponseToDiags("Could not replace SSHKey", err, &resp.Diagnostics)
 		return
 	}
 
@@ -180,22 +164,18 @@ func (r *sshKeyResource) Update(ctx context.Context, req resource.UpdateRequest,
 		PublicKey: plan.PublicKey.ValueString(),
 	}
 
-	sshResp, err := r.client.CreateSSHKey(ctx, reqBody)
+	sshKey, err := r.client.CreateSSHKey(ctx, reqBody)
 	if err != nil {
-		resp.Diagnostics.AddError("Error creating ssh key", "Unexpected error: "+err.Error())
-		return
-	}
-	if sshResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating ssh key", sshResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error creating ssh key", err, &resp.Diagnostics)
 		return
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	plan.PublicKey = types.StringValue(sshResp.SSHKey.PublicKey)
-	plan.Name = types.StringValue(sshResp.SSHKey.Name)
-	plan.CreatedAt = types.Int64Value(sshResp.SSHKey.CreatedAt)
-	plan.Uuid = types.StringValue(sshResp.SSHKey.Uuid)
-	plan.ID = types.StringValue(sshResp.SSHKey.Uuid)
+	plan.PublicKey = types.StringValue(sshKey.PublicKey)
+	plan.Name = types.StringValue(sshKey.Name)
+	plan.CreatedAt = types.Int64Value(sshKey.CreatedAt)
+	plan.Uuid = types.StringValue(sshKey.Uuid)
+	plan.ID = types.StringValue(sshKey.Uuid)
 
 	// Set state to fully populated data
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
@@ -216,10 +196,7 @@ func (r *sshKeyResource) Delete(ctx context.Context, req resource.DeleteRequest,
 
 	err := r.client.DeleteSSHKey(ctx, data.Uuid.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting SSHKey",
-			"Unexpected error: "+err.Error(),
-		)
+		AddErrorResponseToDiags("Error Deleting SSHKey", err, &resp.Diagnostics)
 		return
 	}
 }
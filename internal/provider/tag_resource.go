@@ -93,21 +93,13 @@ func (r *tagResource) Create(ctx context.Context, req resource.CreateRequest, re
 	}
 
 	// Create API call logic
-	tagResp, err := r.client.CreateTag(ctx, data.Name.ValueString())
+	tag, err := r.client.CreateTag(ctx, data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating tag",
-			"Unexpected error: "+err.Error(),
-		)
+		AddErrorResponseToDiags("Error creating tag", err, &resp.Diagnostics)
 		return
 	}
 
-	if tagResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error creating tag", tagResp.ErrorResponse, &resp.Diagnostics)
-		return
-	}
-
-	resp.Diagnostics.Append(tagRespToPlan(ctx, tagResp.Tag, &data)...)
+	resp.Diagnostics.Append(tagRespToPlan(ctx, tag, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -160,21 +152,13 @@ func (r *tagResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	}
 
 	// Read API call logic
-	tagResp, err := r.client.GetTag(ctx, data.ID.ValueString())
+	tag, err := r.client.GetTag(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading tag",
-			"Unexpected error: "+err.Error(),
-		)
+		AddErrorResponseToDiags("Error reading tag", err, &resp.Diagnostics)
 		return
 	}
 
-	if tagResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error reading tag", tagResp.ErrorResponse, &resp.Diagnostics)
-		return
-	}
-
-	tagRespToPlan(ctx, tagResp.Tag, &data)
+	tagRespToPlan(ctx, tag, &data)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -193,21 +177,13 @@ func (r *tagResource) Update(ctx context.Context, req resource.UpdateRequest, re
 	// name attribute was changed
 	oldName, newName := state.Name.ValueString(), plan.Name.ValueString()
 
-	tagResp, err := r.client.UpdateTag(ctx, oldName, newName)
+	tag, err := r.client.UpdateTag(ctx, oldName, newName)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error updating tag",
-			"Could not update tag, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	if tagResp.ErrorResponse != nil {
-		AddErrorResponseToDiags("Error updating tag", tagResp.ErrorResponse, &resp.Diagnostics)
+		AddErrorResponseToDiags("Error updating tag", err, &resp.Diagnostics)
 		return
 	}
 
-	tagRespToPlan(ctx, tagResp.Tag, &plan)
+	tagRespToPlan(ctx, tag, &plan)
 
 	// Save updated plan into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
@@ -226,10 +202,7 @@ func (r *tagResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 	// Delete API call logic
 	err := r.client.DeleteTag(ctx, data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting Tag",
-			"Could not delete tag, unexpected error: "+err.Error(),
-		)
+		AddErrorResponseToDiags("Error Deleting Tag", err, &resp.Diagnostics)
 		return
 	}
 }
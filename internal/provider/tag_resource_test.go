@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"strconv"
 	"testing"
@@ -10,10 +11,10 @@ import (
 )
 
 func TestAccTagResource(t *testing.T) {
-	tagName := randomTagName("tag")
+	tagName := randomTagName(t, "tag")
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -40,6 +41,15 @@ resource "i3dnet_tag" "test" {
 	})
 }
 
-func randomTagName(prefix string) string {
+// randomTagName returns a unique tag name. When API exchanges are recorded or
+// replayed, the name is derived from the test name instead, so that the
+// replayed requests match the recorded ones.
+func randomTagName(t *testing.T, prefix string) string {
+	if cassetteMode() != 0 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(t.Name()))
+		return prefix + strconv.FormatUint(uint64(h.Sum32()), 10)
+	}
+
 	return prefix + strconv.Itoa(rand.Int())
 }
@@ -75,10 +75,7 @@ func (d *tagsDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	tagsData, err := d.client.ListTags(ctx, data.Name.ValueString())
 
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to Read i3D.net tags",
-			err.Error(),
-		)
+		AddErrorResponseToDiags("Unable to Read i3D.net tags", err, &resp.Diagnostics)
 		return
 	}
 
@@ -9,12 +9,12 @@ import (
 )
 
 func TestAccTagDataSource(t *testing.T) {
-	firstTag := randomTagName("testTag")
+	firstTag := randomTagName(t, "testTag")
 
-	createTestTags(t, firstTag, randomTagName("secondTag"))
+	createTestTags(t, firstTag, randomTagName(t, "secondTag"))
 
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(t),
 		Steps: []resource.TestStep{
 			// Read testing
 			{